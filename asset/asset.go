@@ -0,0 +1,120 @@
+// Package asset fingerprints built CSS for cache busting, naming each file after a hash of its
+// content and recording the names in a manifest, and generates Go code referencing it.
+package asset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HashLength is the number of hex characters of the content hash in a hashed file name
+const HashLength = 8
+
+// Hash renames the file at path to include a hash of its content, e.g. output.css to
+// output.3f9a1c2b.css, and removes the hashed files of previous builds. It returns the path of
+// the hashed file.
+func Hash(logger *slog.Logger, path string) (string, error) {
+	sum, err := digest(path, sha256.New())
+	if err != nil {
+		return "", err
+	}
+	hashed := filepath.Join(filepath.Dir(path), HashedName(filepath.Base(path), hex.EncodeToString(sum)[:HashLength]))
+
+	if err = RemoveStale(logger, path, filepath.Base(hashed)); err != nil {
+		return "", err
+	}
+	if err = os.Rename(path, hashed); err != nil {
+		return "", err
+	}
+	logger.Debug("Hashed asset", "path", path, "hashed", hashed)
+	return hashed, nil
+}
+
+// HashedName returns the name with the hash inserted before its extension
+func HashedName(name string, hash string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// IsHashed reports whether the name contains a content hash, so it never changes content and
+// can be cached forever
+func IsHashed(name string) bool {
+	_, ok := unhash(filepath.Base(name))
+	return ok
+}
+
+// Unhash returns the name without its content hash, the name itself when it has none
+func Unhash(name string) string {
+	if logical, ok := unhash(name); ok {
+		return logical
+	}
+	return name
+}
+
+func unhash(name string) (string, bool) {
+	// The hash of a precompressed sibling precedes the extension of the asset, e.g. output.3f9a1c2b.css.gz
+	base := TrimCompressed(name)
+	compressedExt := strings.TrimPrefix(name, base)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	hashExt := filepath.Ext(stem)
+	if len(hashExt) != HashLength+1 || !isHex(hashExt[1:]) {
+		return "", false
+	}
+	return strings.TrimSuffix(stem, hashExt) + ext + compressedExt, true
+}
+
+// RemoveStale removes the hashed files of path, and their precompressed siblings, left by
+// previous builds, except keep
+func RemoveStale(logger *slog.Logger, path string, keep string) error {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	for _, entry := range entries {
+		if entry.IsDir() || TrimCompressed(entry.Name()) == keep {
+			continue
+		}
+		if logical, ok := unhash(entry.Name()); !ok || TrimCompressed(logical) != name {
+			continue
+		}
+		stale := filepath.Join(filepath.Dir(path), entry.Name())
+		if err = os.Remove(stale); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		logger.Debug("Removed stale asset", "path", stale)
+	}
+	return nil
+}
+
+// digest returns the digest of the file at path computed by h
+func digest(path string, h hash.Hash) ([]byte, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is the output of a build target
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
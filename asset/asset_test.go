@@ -0,0 +1,83 @@
+package asset_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper function to create a test logger that discards output
+func testLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Renames to content hash and removes stale files", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		content := []byte("body{color:red}")
+		sum := sha256.Sum256(content)
+		expected := filepath.Join(dir, "output."+hex.EncodeToString(sum[:])[:asset.HashLength]+".css")
+		path := filepath.Join(dir, "output.css")
+		require.NoError(t, os.WriteFile(path, content, 0600))
+		stale := filepath.Join(dir, "output.0123abcd.css")
+		require.NoError(t, os.WriteFile(stale, []byte("old"), 0600))
+		staleGzip := stale + asset.GzipExt
+		require.NoError(t, os.WriteFile(staleGzip, []byte("old"), 0600))
+		other := filepath.Join(dir, "output.min.css")
+		require.NoError(t, os.WriteFile(other, []byte("other"), 0600))
+
+		hashed, err := asset.Hash(testLogger(), path)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, hashed)
+		assert.FileExists(t, hashed)
+		assert.NoFileExists(t, path)
+		assert.NoFileExists(t, stale)
+		assert.NoFileExists(t, staleGzip)
+		assert.FileExists(t, other)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := asset.Hash(testLogger(), filepath.Join(t.TempDir(), "output.css"))
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestIsHashed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected bool
+		logical  string
+	}{
+		{name: "output.3f9a1c2b.css", expected: true, logical: "output.css"},
+		{name: "css/output.3f9a1c2b.css", expected: true, logical: "css/output.css"},
+		{name: "output.3f9a1c2b.css.br", expected: true, logical: "output.css.br"},
+		{name: "output.css.gz", expected: false, logical: "output.css.gz"},
+		{name: "output.css", expected: false, logical: "output.css"},
+		{name: "output.min.css", expected: false, logical: "output.min.css"},
+		{name: "output.3F9A1C2B.css", expected: false, logical: "output.3F9A1C2B.css"},
+		{name: "output.3f9a1c.css", expected: false, logical: "output.3f9a1c.css"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.expected, asset.IsHashed(test.name))
+			assert.Equal(t, test.logical, asset.Unhash(test.name))
+		})
+	}
+}
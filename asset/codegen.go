@@ -0,0 +1,142 @@
+package asset
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Asset is a built file referenced by generated Go code
+type Asset struct {
+	// Name is the name of the asset, e.g. admin-panel. Its constants are named after it, e.g.
+	// AdminPanelPath.
+	Name string
+	// Path is the path of the file relative to the directory of the generated file
+	Path string
+	// Integrity is the subresource integrity of the file, its base64 SHA-384 digest prefixed with sha384-
+	Integrity string
+}
+
+// NewAsset returns the asset of the file at filePath, to be referenced from a Go file in dir
+func NewAsset(name string, dir string, filePath string) (Asset, error) {
+	rel, err := filepath.Rel(dir, filePath)
+	if err != nil {
+		return Asset{}, err
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return Asset{}, fmt.Errorf("%w: %s is not within %s", ErrOutsidePackage, filePath, dir)
+	}
+
+	integrity, err := Integrity(filePath)
+	if err != nil {
+		return Asset{}, err
+	}
+	return Asset{Name: name, Path: rel, Integrity: integrity}, nil
+}
+
+// Integrity returns the subresource integrity of the file at path, its SHA-384 digest as used
+// by the integrity attribute of link elements
+func Integrity(path string) (string, error) {
+	sum, err := digest(path, sha512.New384())
+	if err != nil {
+		return "", err
+	}
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// Identifier converts the name, e.g. admin-panel, to an exported Go identifier, e.g. AdminPanel
+func Identifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("Asset")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "Asset"
+	}
+	return b.String()
+}
+
+// GenerateGo returns the source of a Go file in package pkg declaring an embed.FS of the
+// directories of the assets along with the path and integrity of every asset as constants
+func GenerateGo(pkg string, assets []Asset) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPackage, pkg)
+	}
+
+	idents := make(map[string]string, len(assets))
+	var patterns []string
+	for _, a := range assets {
+		if other, ok := idents[Identifier(a.Name)]; ok {
+			return nil, fmt.Errorf("%w: %s and %s", ErrDuplicateIdentifier, other, a.Name)
+		}
+		idents[Identifier(a.Name)] = a.Name
+
+		// The directory is embedded so everything served next to the asset is included, e.g.
+		// the manifest, but the directory of the Go file would embed the Go file itself
+		pattern := path.Dir(a.Path)
+		if pattern == "." {
+			pattern = a.Path
+		}
+		if !slices.Contains(patterns, pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	slices.Sort(patterns)
+
+	var buf bytes.Buffer
+	err := goTemplate.Execute(&buf, struct {
+		Package  string
+		Patterns string
+		Assets   []Asset
+	}{Package: pkg, Patterns: strings.Join(patterns, " "), Assets: assets})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+var goTemplate = template.Must(template.New("go").Funcs(template.FuncMap{"ident": Identifier}).Parse(`// Code generated by go-tw; DO NOT EDIT.
+
+package {{ .Package }}
+
+import "embed"
+
+// FS contains the CSS built by go-tw. The paths of the assets are relative to it.
+//
+//go:embed {{ .Patterns }}
+var FS embed.FS
+{{ range .Assets }}
+const (
+	// {{ ident .Name }}Path is the path of the {{ .Name }} asset in FS.
+	{{ ident .Name }}Path = {{ printf "%q" .Path }}
+	// {{ ident .Name }}Integrity is the subresource integrity of the {{ .Name }} asset.
+	{{ ident .Name }}Integrity = {{ printf "%q" .Integrity }}
+)
+{{ end }}`))
+
+var ErrOutsidePackage = errors.New("asset is not within the directory of the generated file")
+var ErrInvalidPackage = errors.New("invalid package name")
+var ErrDuplicateIdentifier = errors.New("assets have the same Go identifier")
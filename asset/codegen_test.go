@@ -0,0 +1,130 @@
+package asset_test
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAsset(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Relative path and integrity", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		content := []byte("body{color:red}")
+		sum := sha512.Sum384(content)
+		path := filepath.Join(dir, "dist", "app.3f9a1c2b.css")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, content, 0600))
+
+		a, err := asset.NewAsset("app", dir, path)
+
+		require.NoError(t, err)
+		assert.Equal(t, asset.Asset{
+			Name:      "app",
+			Path:      "dist/app.3f9a1c2b.css",
+			Integrity: "sha384-" + base64.StdEncoding.EncodeToString(sum[:]),
+		}, a)
+	})
+
+	t.Run("Outside directory", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+
+		_, err := asset.NewAsset("app", filepath.Join(dir, "web"), filepath.Join(dir, "dist", "app.css"))
+
+		assert.ErrorIs(t, err, asset.ErrOutsidePackage)
+	})
+}
+
+func TestGenerateGo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Declares embed and constants", func(t *testing.T) {
+		t.Parallel()
+
+		src, err := asset.GenerateGo("web", []asset.Asset{
+			{Name: "app", Path: "dist/app.3f9a1c2b.css", Integrity: "sha384-abc"},
+			{Name: "admin-panel", Path: "dist/admin/admin-panel.0c1d7e4a.css", Integrity: "sha384-def"},
+			{Name: "print", Path: "print.css", Integrity: "sha384-ghi"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, `// Code generated by go-tw; DO NOT EDIT.
+
+package web
+
+import "embed"
+
+// FS contains the CSS built by go-tw. The paths of the assets are relative to it.
+//
+//go:embed dist dist/admin print.css
+var FS embed.FS
+
+const (
+	// AppPath is the path of the app asset in FS.
+	AppPath = "dist/app.3f9a1c2b.css"
+	// AppIntegrity is the subresource integrity of the app asset.
+	AppIntegrity = "sha384-abc"
+)
+
+const (
+	// AdminPanelPath is the path of the admin-panel asset in FS.
+	AdminPanelPath = "dist/admin/admin-panel.0c1d7e4a.css"
+	// AdminPanelIntegrity is the subresource integrity of the admin-panel asset.
+	AdminPanelIntegrity = "sha384-def"
+)
+
+const (
+	// PrintPath is the path of the print asset in FS.
+	PrintPath = "print.css"
+	// PrintIntegrity is the subresource integrity of the print asset.
+	PrintIntegrity = "sha384-ghi"
+)
+`, string(src))
+	})
+
+	t.Run("Invalid package", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := asset.GenerateGo("go-tw", nil)
+
+		assert.ErrorIs(t, err, asset.ErrInvalidPackage)
+	})
+
+	t.Run("Duplicate identifier", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := asset.GenerateGo("web", []asset.Asset{{Name: "admin-panel", Path: "a.css"}, {Name: "admin_panel", Path: "b.css"}})
+
+		assert.ErrorIs(t, err, asset.ErrDuplicateIdentifier)
+	})
+}
+
+func TestIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "app", expected: "App"},
+		{name: "admin-panel", expected: "AdminPanel"},
+		{name: "admin_panel.v2", expected: "AdminPanelV2"},
+		{name: "2col", expected: "Asset2col"},
+		{name: "--", expected: "Asset"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.expected, asset.Identifier(test.name))
+		})
+	}
+}
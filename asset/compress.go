@@ -0,0 +1,190 @@
+package asset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	// GzipExt is the extension of the gzip sibling of an asset
+	GzipExt = ".gz"
+	// BrotliExt is the extension of the brotli sibling of an asset
+	BrotliExt = ".br"
+)
+
+// Compression controls which precompressed siblings of an asset are written
+type Compression struct {
+	// Gzip writes a gzip sibling, e.g. output.css.gz.
+	Gzip bool
+	// GzipLevel is the gzip compression level from 1 to 9, gzip.BestCompression when zero.
+	GzipLevel int
+	// Brotli writes a brotli sibling, e.g. output.css.br.
+	Brotli bool
+	// BrotliLevel is the brotli compression level from 1 to 11, brotli.BestCompression when zero.
+	BrotliLevel int
+}
+
+// encoding compresses an asset into a sibling with its extension
+type encoding struct {
+	ext       string
+	newWriter func(w io.Writer, level int) (io.WriteCloser, error)
+	newReader func(r io.Reader) (io.Reader, error)
+	level     int
+	// maxLevel is the best compression, used when no level is set
+	maxLevel int
+}
+
+// encodings returns the encodings enabled by the compression
+func (c Compression) encodings() []encoding {
+	var encodings []encoding
+	if c.Gzip {
+		encodings = append(encodings, encoding{
+			ext: GzipExt,
+			newWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+				return gzip.NewWriterLevel(w, level)
+			},
+			newReader: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+			level:    c.GzipLevel,
+			maxLevel: gzip.BestCompression,
+		})
+	}
+	if c.Brotli {
+		encodings = append(encodings, encoding{
+			ext: BrotliExt,
+			newWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+				return brotli.NewWriterLevel(w, level), nil
+			},
+			newReader: func(r io.Reader) (io.Reader, error) {
+				return brotli.NewReader(r), nil
+			},
+			level:    c.BrotliLevel,
+			maxLevel: brotli.BestCompression,
+		})
+	}
+	return encodings
+}
+
+// Validate checks the compression levels are in range
+func (c Compression) Validate() error {
+	for _, e := range c.encodings() {
+		if e.level < 0 || e.level > e.maxLevel {
+			return fmt.Errorf("%w: %s level must be from 1 to %d: %d", ErrInvalidLevel, strings.TrimPrefix(e.ext, "."), e.maxLevel, e.level)
+		}
+	}
+	return nil
+}
+
+// Compress writes the precompressed siblings of the asset at path, e.g. output.css.gz and
+// output.css.br. A sibling is only rewritten when the asset changed, so its modification time
+// and ETag stay the same across builds producing the same CSS.
+func Compress(logger *slog.Logger, path string, c Compression) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	//nolint:gosec // G304: path is the output of a build target
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range c.encodings() {
+		sibling := path + e.ext
+		if current(sibling, e, content) {
+			logger.Debug("Compressed asset is up to date", "path", sibling)
+			continue
+		}
+		level := e.level
+		if level == 0 {
+			level = e.maxLevel
+		}
+		if err = writeCompressed(sibling, e, level, content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", sibling, err)
+		}
+		logger.Debug("Compressed asset", "path", sibling, "level", level)
+	}
+	return nil
+}
+
+// current reports whether the sibling exists and decompresses to the content
+func current(sibling string, e encoding, content []byte) bool {
+	f, err := os.Open(sibling) //nolint:gosec // G304: sibling is next to the output of a build target
+	if err != nil {
+		return false
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	r, err := e.newReader(f)
+	if err != nil {
+		return false
+	}
+	// Reading one byte more than the content detects a longer sibling
+	existing, err := io.ReadAll(io.LimitReader(r, int64(len(content))+1))
+	return err == nil && bytes.Equal(existing, content)
+}
+
+// writeCompressed compresses the content to a temp file renamed into place once complete, so a
+// server never serves a partially written sibling
+func writeCompressed(sibling string, e encoding, level int, content []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(sibling), "."+filepath.Base(sibling)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := f.Name()
+	renamed := false
+	defer func() {
+		if !renamed {
+			_ = os.Remove(tempPath)
+		}
+	}()
+
+	w, err := e.newWriter(f, level)
+	if err == nil {
+		_, err = w.Write(content)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // G302: compressed assets are served and are not sensitive
+	if err = os.Chmod(tempPath, 0644); err != nil {
+		return err
+	}
+	if err = os.Rename(tempPath, sibling); err != nil {
+		return err
+	}
+	renamed = true
+	return nil
+}
+
+// TrimCompressed returns the name of the asset a precompressed sibling belongs to, the name
+// itself when it is not a sibling
+func TrimCompressed(name string) string {
+	for _, ext := range []string{GzipExt, BrotliExt} {
+		if trimmed, ok := strings.CutSuffix(name, ext); ok {
+			return trimmed
+		}
+	}
+	return name
+}
+
+var ErrInvalidLevel = errors.New("invalid compression level")
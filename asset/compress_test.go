@@ -0,0 +1,77 @@
+package asset_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Writes siblings only when changed", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path := filepath.Join(dir, "output.css")
+		content := bytes.Repeat([]byte(".flex{display:flex}\n"), 100)
+		require.NoError(t, os.WriteFile(path, content, 0600))
+		c := asset.Compression{Gzip: true, Brotli: true, BrotliLevel: 5}
+
+		require.NoError(t, asset.Compress(testLogger(), path, c))
+
+		//nolint:gosec // G304: Reading from test temp file, safe
+		gz, err := os.Open(path + asset.GzipExt)
+		require.NoError(t, err)
+		defer func() {
+			_ = gz.Close()
+		}()
+		gr, err := gzip.NewReader(gz)
+		require.NoError(t, err)
+		decompressed, err := io.ReadAll(gr)
+		require.NoError(t, err)
+		assert.Equal(t, content, decompressed)
+
+		//nolint:gosec // G304: Reading from test temp file, safe
+		br, err := os.ReadFile(path + asset.BrotliExt)
+		require.NoError(t, err)
+		decompressed, err = io.ReadAll(brotli.NewReader(bytes.NewReader(br)))
+		require.NoError(t, err)
+		assert.Equal(t, content, decompressed)
+		assert.Less(t, len(br), len(content))
+
+		// Unchanged content keeps the siblings as they are
+		old := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(path+asset.GzipExt, old, old))
+		require.NoError(t, asset.Compress(testLogger(), path, c))
+		info, err := os.Stat(path + asset.GzipExt)
+		require.NoError(t, err)
+		assert.True(t, info.ModTime().Equal(old))
+
+		// Changed content rewrites them
+		require.NoError(t, os.WriteFile(path, []byte(".grid{display:grid}"), 0600))
+		require.NoError(t, asset.Compress(testLogger(), path, c))
+		info, err = os.Stat(path + asset.GzipExt)
+		require.NoError(t, err)
+		assert.False(t, info.ModTime().Equal(old))
+	})
+
+	t.Run("Invalid level", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "output.css")
+		require.NoError(t, os.WriteFile(path, []byte("body{}"), 0600))
+
+		err := asset.Compress(testLogger(), path, asset.Compression{Gzip: true, GzipLevel: 12})
+
+		require.ErrorIs(t, err, asset.ErrInvalidLevel)
+		assert.NoFileExists(t, path+asset.GzipExt)
+	})
+}
@@ -0,0 +1,81 @@
+package asset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ManifestFileName is the name of the manifest written next to hashed assets
+const ManifestFileName = "manifest.json"
+
+// Manifest maps the logical names of assets, e.g. output.css, to their hashed names, e.g.
+// output.3f9a1c2b.css. Names are relative to the directory of the manifest.
+type Manifest map[string]string
+
+// Path returns the hashed name of the asset, the logical name itself when it is not in the
+// manifest
+func (m Manifest) Path(name string) string {
+	if hashed, ok := m[name]; ok {
+		return hashed
+	}
+	return name
+}
+
+// ReadManifest reads the manifest at path, an empty manifest when it does not exist
+func ReadManifest(path string) (Manifest, error) {
+	//nolint:gosec // G304: path is the manifest of an output directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Manifest{}, nil
+		}
+		return nil, err
+	}
+
+	m := Manifest{}
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	return m, nil
+}
+
+// WriteManifest writes the manifest to path
+func WriteManifest(path string, m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	//nolint:gosec // G306: the manifest is served alongside the assets and is not sensitive
+	return os.WriteFile(path, data, 0644)
+}
+
+// UpdateManifest records the hashed paths in the manifest of their directory, keeping the
+// entries of other assets
+func UpdateManifest(hashed ...string) error {
+	byDir := make(map[string][]string)
+	for _, path := range hashed {
+		byDir[filepath.Dir(path)] = append(byDir[filepath.Dir(path)], filepath.Base(path))
+	}
+
+	for dir, names := range byDir {
+		path := filepath.Join(dir, ManifestFileName)
+		m, err := ReadManifest(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		for _, name := range names {
+			m[Unhash(name)] = name
+		}
+		if err = WriteManifest(path, m); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+var ErrInvalidManifest = errors.New("invalid manifest")
@@ -0,0 +1,55 @@
+package asset_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateManifest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Keeps entries of other assets", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path := filepath.Join(dir, asset.ManifestFileName)
+		require.NoError(t, asset.WriteManifest(path, asset.Manifest{
+			"admin.css": "admin.0123abcd.css",
+			"app.css":   "app.0123abcd.css",
+		}))
+
+		require.NoError(t, asset.UpdateManifest(filepath.Join(dir, "app.3f9a1c2b.css")))
+
+		m, err := asset.ReadManifest(path)
+		require.NoError(t, err)
+		assert.Equal(t, asset.Manifest{
+			"admin.css": "admin.0123abcd.css",
+			"app.css":   "app.3f9a1c2b.css",
+		}, m)
+		assert.Equal(t, "app.3f9a1c2b.css", m.Path("app.css"))
+		assert.Equal(t, "other.css", m.Path("other.css"))
+	})
+
+	t.Run("Missing manifest is empty", func(t *testing.T) {
+		t.Parallel()
+
+		m, err := asset.ReadManifest(filepath.Join(t.TempDir(), asset.ManifestFileName))
+
+		require.NoError(t, err)
+		assert.Empty(t, m)
+	})
+
+	t.Run("Invalid manifest", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), asset.ManifestFileName)
+		require.NoError(t, os.WriteFile(path, []byte("[]"), 0600))
+
+		_, err := asset.ReadManifest(path)
+
+		assert.ErrorIs(t, err, asset.ErrInvalidManifest)
+	})
+}
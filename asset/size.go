@@ -0,0 +1,110 @@
+package asset
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Sizes are the sizes in bytes of an asset as served raw and compressed
+type Sizes struct {
+	Raw    int64 `json:"raw"`
+	Gzip   int64 `json:"gzip"`
+	Brotli int64 `json:"brotli"`
+}
+
+// Measure returns the sizes of the asset at path. The size of a precompressed sibling is used for
+// the encodings c writes, otherwise the asset is compressed in memory with the best compression,
+// so a sibling left behind by an earlier build is never measured.
+func Measure(path string, c Compression) (Sizes, error) {
+	//nolint:gosec // G304: path is the output of a build target
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Sizes{}, err
+	}
+
+	written := make(map[string]bool)
+	for _, e := range c.encodings() {
+		written[e.ext] = true
+	}
+
+	sizes := Sizes{Raw: int64(len(content))}
+	for _, e := range (Compression{Gzip: true, Brotli: true}).encodings() {
+		size, sizeErr := compressedSize(path+e.ext, written[e.ext], e, content)
+		if sizeErr != nil {
+			return Sizes{}, sizeErr
+		}
+		switch e.ext {
+		case GzipExt:
+			sizes.Gzip = size
+		case BrotliExt:
+			sizes.Brotli = size
+		}
+	}
+	return sizes, nil
+}
+
+// compressedSize returns the size of the sibling when it is written by the build, otherwise of
+// the content compressed
+func compressedSize(sibling string, useSibling bool, e encoding, content []byte) (int64, error) {
+	if useSibling {
+		if info, err := os.Stat(sibling); err == nil {
+			return info.Size(), nil
+		}
+	}
+
+	var counter countingWriter
+	w, err := e.newWriter(&counter, e.maxLevel)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = w.Write(content); err != nil {
+		return 0, err
+	}
+	if err = w.Close(); err != nil {
+		return 0, err
+	}
+	return int64(counter), nil
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter int64
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	*c += countingWriter(len(p))
+	return len(p), nil
+}
+
+// SizesPath returns the path of the file recording the sizes of the last build of the output, a
+// hidden file next to it so it is not embedded or served with the output
+func SizesPath(output string) string {
+	return filepath.Join(filepath.Dir(output), "."+filepath.Base(output)+".sizes.json")
+}
+
+// ReadSizes returns the sizes recorded at path, false when none are
+func ReadSizes(path string) (Sizes, bool, error) {
+	//nolint:gosec // G304: path is next to the output of a build target
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Sizes{}, false, nil
+		}
+		return Sizes{}, false, err
+	}
+	var sizes Sizes
+	if err = json.Unmarshal(data, &sizes); err != nil {
+		return Sizes{}, false, err
+	}
+	return sizes, true, nil
+}
+
+// WriteSizes records the sizes at path
+func WriteSizes(path string, sizes Sizes) error {
+	data, err := json.Marshal(sizes)
+	if err != nil {
+		return err
+	}
+	//nolint:gosec // G306: sizes are not sensitive
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
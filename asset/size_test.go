@@ -0,0 +1,78 @@
+package asset_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Compresses in memory without siblings", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "output.css")
+		content := bytes.Repeat([]byte(".flex{display:flex}\n"), 100)
+		require.NoError(t, os.WriteFile(path, content, 0600))
+
+		sizes, err := asset.Measure(path, asset.Compression{})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), sizes.Raw)
+		assert.Positive(t, sizes.Gzip)
+		assert.Less(t, sizes.Gzip, sizes.Raw)
+		assert.Positive(t, sizes.Brotli)
+		assert.Less(t, sizes.Brotli, sizes.Raw)
+	})
+
+	t.Run("Uses sibling sizes", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "output.css")
+		require.NoError(t, os.WriteFile(path, []byte("body{}"), 0600))
+		require.NoError(t, os.WriteFile(path+asset.GzipExt, []byte("123"), 0600))
+		require.NoError(t, os.WriteFile(path+asset.BrotliExt, []byte("12"), 0600))
+
+		sizes, err := asset.Measure(path, asset.Compression{Gzip: true, Brotli: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, asset.Sizes{Raw: 6, Gzip: 3, Brotli: 2}, sizes)
+	})
+
+	t.Run("Ignores siblings that are not written", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "output.css")
+		require.NoError(t, os.WriteFile(path, []byte("body{}"), 0600))
+		require.NoError(t, os.WriteFile(path+asset.GzipExt, []byte("123"), 0600))
+		require.NoError(t, os.WriteFile(path+asset.BrotliExt, []byte("12"), 0600))
+
+		sizes, err := asset.Measure(path, asset.Compression{Gzip: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), sizes.Gzip)
+		assert.NotEqual(t, int64(2), sizes.Brotli)
+		assert.Positive(t, sizes.Brotli)
+	})
+}
+
+func TestReadWriteSizes(t *testing.T) {
+	t.Parallel()
+
+	path := asset.SizesPath(filepath.Join(t.TempDir(), "output.css"))
+	assert.Equal(t, ".output.css.sizes.json", filepath.Base(path))
+
+	_, ok, err := asset.ReadSizes(path)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, asset.WriteSizes(path, asset.Sizes{Raw: 100, Gzip: 40, Brotli: 30}))
+
+	sizes, ok, err := asset.ReadSizes(path)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, asset.Sizes{Raw: 100, Gzip: 40, Brotli: 30}, sizes)
+}
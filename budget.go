@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/progress"
+)
+
+var ErrBudgetExceeded = errors.New("size budget exceeded")
+
+// sizeReport is the sizes of the output of a target compared to its previous build and budget
+type sizeReport struct {
+	name     string
+	sizes    asset.Sizes
+	previous *asset.Sizes
+	budget   *config.Budget
+	// exceeded describes every budget the output is over
+	exceeded []string
+}
+
+// checkBudgets measures the output of every successful target when any target has a budget,
+// records the sizes for the next build and returns the reports
+func checkBudgets(logger *slog.Logger, cfg config.Config, built []builtTarget) []sizeReport {
+	budgets := make(map[string]*config.Budget, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		if target.Budget != nil {
+			budgets[target.Name] = target.Budget
+		}
+	}
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	c := compression(cfg)
+	var reports []sizeReport
+	for _, b := range built {
+		if b.Err != nil {
+			continue
+		}
+		sizes, err := asset.Measure(b.Path, c)
+		if err != nil {
+			logger.Warn("Failed to measure output", "target", b.Target.Name, "error", err)
+			continue
+		}
+		report := sizeReport{name: b.Target.Name, sizes: sizes, budget: budgets[b.Target.Name]}
+
+		sizesPath := asset.SizesPath(resolve(cfg, b.Target.Output))
+		if previous, ok, readErr := asset.ReadSizes(sizesPath); readErr == nil && ok {
+			report.previous = &previous
+		}
+		if err = asset.WriteSizes(sizesPath, sizes); err != nil {
+			logger.Warn("Failed to record output sizes", "target", b.Target.Name, "error", err)
+		}
+
+		if report.budget != nil {
+			report.exceeded = exceeded(sizes, *report.budget)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// exceeded returns a description of every budget the sizes are over
+func exceeded(sizes asset.Sizes, budget config.Budget) []string {
+	var over []string
+	for _, check := range []struct {
+		name   string
+		size   int64
+		budget config.Size
+	}{
+		{"raw", sizes.Raw, budget.Raw},
+		{"gzip", sizes.Gzip, budget.Gzip},
+		{"brotli", sizes.Brotli, budget.Brotli},
+	} {
+		if check.budget > 0 && check.size > int64(check.budget) {
+			over = append(over, fmt.Sprintf("%s %s > %s", check.name, progress.FormatBytes(check.size), progress.FormatBytes(int64(check.budget))))
+		}
+	}
+	return over
+}
+
+// budgetError returns an error when any output is over its budget
+func budgetError(reports []sizeReport) error {
+	var over []string
+	for _, report := range reports {
+		if len(report.exceeded) > 0 {
+			over = append(over, report.name)
+		}
+	}
+	if len(over) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrBudgetExceeded, strings.Join(over, ", "))
+}
+
+// printSizes prints a table of the sizes of the outputs, their change since the previous build
+// and whether they are within budget
+func printSizes(reports []sizeReport) {
+	if len(reports) == 0 {
+		return
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TARGET\tRAW\tGZIP\tBROTLI\tBUDGET")
+	for _, report := range reports {
+		var previous asset.Sizes
+		if report.previous != nil {
+			previous = *report.previous
+		}
+		status := "-"
+		if report.budget != nil {
+			status = "ok"
+		}
+		if len(report.exceeded) > 0 {
+			status = "exceeded: " + strings.Join(report.exceeded, ", ")
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			report.name,
+			formatSize(report.sizes.Raw, previous.Raw, report.previous != nil),
+			formatSize(report.sizes.Gzip, previous.Gzip, report.previous != nil),
+			formatSize(report.sizes.Brotli, previous.Brotli, report.previous != nil),
+			status,
+		)
+	}
+	_ = w.Flush()
+}
+
+// formatSize formats the size with its change since the previous build, when there was one
+func formatSize(size int64, previous int64, hasPrevious bool) string {
+	if !hasPrevious {
+		return progress.FormatBytes(size)
+	}
+	delta := size - previous
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+		delta = -delta
+	}
+	return fmt.Sprintf("%s (%s%s)", progress.FormatBytes(size), sign, progress.FormatBytes(delta))
+}
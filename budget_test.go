@@ -0,0 +1,144 @@
+package main_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	main "github.com/Piszmog/go-tw"
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/tailwind"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// budgetConfig returns a configuration of targets with the budgets, whose outputs contain 32 bytes
+func budgetConfig(t *testing.T, budgets map[string]*config.Budget) config.Config {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.Config{Path: filepath.Join(dir, config.FileName)}
+	for _, name := range []string{"app", "admin"} {
+		output := "dist/" + name + ".css"
+		writeFile(t, dir, output, ".flex{display:flex}.grid{gap:0}\n")
+		cfg.Targets = append(cfg.Targets, config.Target{
+			Target: tailwind.Target{Name: name, Input: "styles/" + name + ".css", Output: output},
+			Budget: budgets[name],
+		})
+	}
+	return cfg
+}
+
+func TestCheckBudgets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Over budget", func(t *testing.T) {
+		t.Parallel()
+		cfg := budgetConfig(t, map[string]*config.Budget{
+			"app":   {Raw: 16},
+			"admin": {Raw: 1024},
+		})
+
+		reports, err := main.CheckBudgets(cfg)
+
+		require.ErrorIs(t, err, main.ErrBudgetExceeded)
+		assert.Equal(t, "size budget exceeded: app", err.Error())
+		require.Len(t, reports, 2)
+		assert.Equal(t, []string{"raw 32 B > 16 B"}, reports[0].Exceeded)
+		assert.Empty(t, reports[1].Exceeded)
+	})
+
+	t.Run("Zero budget is ignored", func(t *testing.T) {
+		t.Parallel()
+		cfg := budgetConfig(t, map[string]*config.Budget{
+			"app": {Raw: 0, Gzip: 0, Brotli: 0},
+		})
+
+		reports, err := main.CheckBudgets(cfg)
+
+		require.NoError(t, err)
+		require.Len(t, reports, 2)
+		assert.Empty(t, reports[0].Exceeded)
+	})
+
+	t.Run("Compressed budgets", func(t *testing.T) {
+		t.Parallel()
+		cfg := budgetConfig(t, map[string]*config.Budget{
+			"app": {Raw: 1024, Gzip: 1, Brotli: 1},
+		})
+
+		reports, err := main.CheckBudgets(cfg)
+
+		require.ErrorIs(t, err, main.ErrBudgetExceeded)
+		require.Len(t, reports[0].Exceeded, 2)
+		assert.Contains(t, reports[0].Exceeded[0], "gzip")
+		assert.Contains(t, reports[0].Exceeded[1], "brotli")
+	})
+
+	t.Run("Previous sizes", func(t *testing.T) {
+		t.Parallel()
+		cfg := budgetConfig(t, map[string]*config.Budget{"app": {Raw: 1024}})
+		previous := asset.Sizes{Raw: 20, Gzip: 10, Brotli: 8}
+		require.NoError(t, asset.WriteSizes(asset.SizesPath(filepath.Join(cfg.Dir(), "dist", "app.css")), previous))
+
+		reports, err := main.CheckBudgets(cfg)
+
+		require.NoError(t, err)
+		require.NotNil(t, reports[0].Previous)
+		assert.Equal(t, previous, *reports[0].Previous)
+		assert.Nil(t, reports[1].Previous, "admin was not measured before")
+		assert.Equal(t, int64(32), reports[0].Sizes.Raw)
+
+		// The sizes are recorded for the next build
+		reports, err = main.CheckBudgets(cfg)
+		require.NoError(t, err)
+		require.NotNil(t, reports[0].Previous)
+		assert.Equal(t, reports[0].Sizes, *reports[0].Previous)
+	})
+
+	t.Run("No budgets", func(t *testing.T) {
+		t.Parallel()
+		cfg := budgetConfig(t, nil)
+
+		reports, err := main.CheckBudgets(cfg)
+
+		require.NoError(t, err)
+		assert.Empty(t, reports)
+		assert.NoFileExists(t, asset.SizesPath(filepath.Join(cfg.Dir(), "dist", "app.css")))
+	})
+
+	t.Run("Build fails over budget", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+		p.cfg.Targets[0].Budget = &config.Budget{Raw: 8}
+
+		err := p.build()
+
+		require.ErrorIs(t, err, main.ErrBudgetExceeded)
+		assert.Contains(t, err.Error(), "app")
+		assert.FileExists(t, filepath.Join(p.dir, "dist", "app.css"), "the output is still written")
+	})
+}
+
+func TestFormatSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		size        int64
+		previous    int64
+		hasPrevious bool
+		expected    string
+	}{
+		{"No previous build", 2048, 0, false, "2.0 KiB"},
+		{"Grown", 2048, 1024, true, "2.0 KiB (+1.0 KiB)"},
+		{"Shrunk", 1024, 1536, true, "1.0 KiB (-512 B)"},
+		{"Unchanged", 1024, 1024, true, "1.0 KiB (+0 B)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, main.FormatSize(tt.size, tt.previous, tt.hasPrevious))
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/tailwind"
+)
+
+var ErrNoTargets = errors.New("no targets are configured")
+var ErrUnknownTarget = errors.New("unknown target")
+var ErrBuildFailed = errors.New("build failed")
+
+const buildUsage = `Usage:
+  go-tw build [-j N] [-hash] [-force] [target...]   Build the targets of the configuration file, or only the named ones`
+
+// build builds the targets of the configuration file concurrently with a single tailwindcss
+// install, failing if any of them fails
+func build(ctx context.Context, opts tailwind.Options, cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	concurrency := flags.Int("j", cfg.Concurrency, "number of targets built at the same time, the number of CPUs when 0")
+	hash := flags.Bool("hash", cfg.Hash, "rename the outputs to include a hash of their content and write manifest.json")
+	force := flags.Bool("force", false, "rebuild every target even when its inputs are unchanged")
+	flags.Usage = func() { fmt.Println(buildUsage) }
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	targets, err := selectTargets(cfg.Targets, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	// The fingerprints depend on the exact version, so it is resolved before the targets are built
+	var inc *incremental
+	if cfg.Incremental {
+		_, version, ensureErr := tailwind.Ensure(ctx, opts)
+		if ensureErr != nil {
+			return ensureErr
+		}
+		opts.Version = version
+		if inc, err = newIncremental(cfg, version, *hash); err != nil {
+			return fmt.Errorf("failed to fingerprint sources: %w", err)
+		}
+	}
+
+	built := make([]builtTarget, len(targets))
+	var pending []tailwind.Target
+	for i, target := range targets {
+		built[i] = builtTarget{TargetResult: tailwind.TargetResult{Target: target}, Path: resolve(cfg, target.Output)}
+		if inc == nil {
+			pending = append(pending, target)
+			continue
+		}
+		if built[i].Fingerprint, err = inc.fingerprint(target); err != nil {
+			return fmt.Errorf("failed to fingerprint %s: %w", target.Name, err)
+		}
+		if !*force && inc.upToDate(&built[i]) {
+			built[i].Skipped = true
+			continue
+		}
+		inc.invalidate(&built[i])
+		pending = append(pending, target)
+	}
+
+	if len(pending) > 0 {
+		results, buildErr := tailwind.BuildTargets(ctx, tailwind.TargetsOptions{
+			Options:     opts,
+			Targets:     pending,
+			Dir:         cfg.Dir(),
+			Concurrency: *concurrency,
+		})
+		if buildErr != nil {
+			return buildErr
+		}
+		for _, result := range results {
+			i := slices.IndexFunc(built, func(b builtTarget) bool { return b.Target.Name == result.Target.Name })
+			built[i].TargetResult = result
+		}
+	}
+
+	if *hash {
+		hashOutputs(opts.Logger, built)
+	}
+	if cfg.Compress != nil {
+		compressOutputs(opts.Logger, built, compression(cfg))
+	}
+	if inc != nil {
+		inc.record(opts.Logger, built)
+	}
+
+	printResults(cfg.Dir(), built)
+	reports := checkBudgets(opts.Logger, cfg, built)
+	printSizes(reports)
+
+	var failed int
+	for _, result := range built {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d targets failed", ErrBuildFailed, failed, len(built))
+	}
+
+	if cfg.Codegen != nil {
+		if err = generateGo(cfg, *hash); err != nil {
+			return fmt.Errorf("failed to generate Go code: %w", err)
+		}
+	}
+	return budgetError(reports)
+}
+
+// generateGo writes the Go file referencing the outputs of every configured target, not only
+// those just built, so building a subset of the targets keeps the others
+func generateGo(cfg config.Config, hashed bool) error {
+	file, src, err := goSource(cfg, hashed)
+	if err != nil {
+		return err
+	}
+	//nolint:gosec // G306: generated source is committed and is not sensitive
+	if err = os.WriteFile(file, src, 0644); err != nil {
+		return err
+	}
+	fmt.Println("Generated " + relPath(cfg.Dir(), file))
+	return nil
+}
+
+// goSource returns the path and source of the Go file referencing the outputs of every
+// configured target
+func goSource(cfg config.Config, hashed bool) (string, []byte, error) {
+	file := resolve(cfg, cfg.Codegen.File)
+	dir := filepath.Dir(file)
+	pkg := cfg.Codegen.Package
+	if pkg == "" {
+		pkg = filepath.Base(dir)
+	}
+
+	assets := make([]asset.Asset, 0, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		path, err := outputPath(resolve(cfg, target.Output), hashed)
+		if err != nil {
+			return "", nil, err
+		}
+		a, err := asset.NewAsset(target.Name, dir, path)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to reference target %s: %w", target.Name, err)
+		}
+		assets = append(assets, a)
+	}
+
+	src, err := asset.GenerateGo(pkg, assets)
+	if err != nil {
+		return "", nil, err
+	}
+	return file, src, nil
+}
+
+// outputPath returns the path of the latest build of the output, looking up its hashed name in
+// the manifest when the outputs are hashed
+func outputPath(output string, hashed bool) (string, error) {
+	if !hashed {
+		return output, nil
+	}
+	m, err := asset.ReadManifest(filepath.Join(filepath.Dir(output), asset.ManifestFileName))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(output), m.Path(filepath.Base(output))), nil
+}
+
+// selectTargets returns the configured targets with the given names, every target when no
+// names are given
+func selectTargets(configured []config.Target, names []string) ([]tailwind.Target, error) {
+	if len(configured) == 0 {
+		return nil, fmt.Errorf("%w: add targets to %s", ErrNoTargets, config.FileName)
+	}
+
+	var targets []tailwind.Target
+	for _, target := range configured {
+		if len(names) > 0 && !slices.Contains(names, target.Name) {
+			continue
+		}
+		targets = append(targets, target.Target)
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(configured, func(target config.Target) bool { return target.Name == name }) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTarget, name)
+		}
+	}
+	return targets, nil
+}
+
+// builtTarget is the result of building a target along with the path of its output
+type builtTarget struct {
+	tailwind.TargetResult
+	// Path is where the output of the target ended up, e.g. after it was hashed
+	Path string
+	// Skipped is whether the target was not rebuilt because its inputs are unchanged
+	Skipped bool
+	// Fingerprint is of the inputs of the target, empty when not building incrementally
+	Fingerprint string
+}
+
+// hashOutputs renames the output of every successful target to include a hash of its content
+// and records the hashed names in the manifest of each output directory. A target fails when
+// its output cannot be hashed.
+func hashOutputs(logger *slog.Logger, built []builtTarget) {
+	var hashed []string
+	for i := range built {
+		if built[i].Err != nil || built[i].Skipped {
+			continue
+		}
+		path, err := asset.Hash(logger, built[i].Path)
+		if err != nil {
+			built[i].Err = fmt.Errorf("failed to hash %s: %w", built[i].Target.Name, err)
+			continue
+		}
+		built[i].Path = path
+		hashed = append(hashed, path)
+	}
+
+	if err := asset.UpdateManifest(hashed...); err != nil {
+		for i := range built {
+			if built[i].Err == nil {
+				built[i].Err = err
+			}
+		}
+	}
+}
+
+// compressOutputs writes the precompressed siblings of the output of every successful target,
+// including those that are up to date so enabling compression does not require a rebuild
+func compressOutputs(logger *slog.Logger, built []builtTarget, c asset.Compression) {
+	for i := range built {
+		if built[i].Err != nil {
+			continue
+		}
+		if err := asset.Compress(logger, built[i].Path, c); err != nil {
+			built[i].Err = fmt.Errorf("failed to compress %s: %w", built[i].Target.Name, err)
+		}
+	}
+}
+
+// compression returns the precompressed siblings the build writes, none when compression is not
+// configured
+func compression(cfg config.Config) asset.Compression {
+	if cfg.Compress == nil {
+		return asset.Compression{}
+	}
+	return asset.Compression{
+		Gzip:        cfg.Compress.Gzip,
+		GzipLevel:   cfg.Compress.GzipLevel,
+		Brotli:      cfg.Compress.Brotli,
+		BrotliLevel: cfg.Compress.BrotliLevel,
+	}
+}
+
+// printResults prints a table of the results, followed by the output of the failed targets
+func printResults(dir string, built []builtTarget) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TARGET\tSTATUS\tDURATION\tOUTPUT")
+	for _, result := range built {
+		status := "ok"
+		if result.Err != nil {
+			status = "failed"
+		} else if result.Skipped {
+			status = "up to date"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Target.Name, status, result.Duration.Round(time.Millisecond), relPath(dir, result.Path))
+	}
+	_ = w.Flush()
+
+	for _, result := range built {
+		if result.Err == nil {
+			continue
+		}
+		fmt.Println()
+		fmt.Println(result.Err)
+		if output := strings.TrimSpace(string(result.Output)); output != "" {
+			fmt.Println(output)
+		}
+	}
+}
+
+// resolve returns the path of p, a path in the configuration file that is relative to the file
+// unless it is absolute
+func resolve(cfg config.Config, p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(cfg.Dir(), p)
+}
+
+// relPath returns path relative to dir for display, path itself when it is not within dir
+func relPath(dir string, path string) string {
+	if dir == "" {
+		return path
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Piszmog/go-tw/client"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/progress"
+)
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+const cacheUsage = `Usage:
+  go-tw cache list              List the installed tailwindcss versions
+  go-tw cache prune [-keep N]   Delete all but the N most recently used versions [default: 1]
+  go-tw cache clean             Delete every installed version, interrupted download and cached lookup
+  go-tw cache path              Print the cache directory`
+
+// cache manages the tailwindcss binaries in the download directory
+func cache(logger *slog.Logger, cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Println(cacheUsage)
+		return nil
+	}
+
+	downloadDir, err := fs.ResolveDownloadDir(cfg.CacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to determine directory to download tailwind to: %w", err)
+	}
+
+	switch args[0] {
+	case "prune", "clean":
+		// Binaries are deleted, so wait for any go-tw process that is installing or starting one
+		cacheLock, lockErr := fs.AcquireLock(context.Background(), logger, downloadDir, time.Duration(cfg.LockTimeout))
+		if lockErr != nil {
+			return fmt.Errorf("failed to lock cache directory: %w", lockErr)
+		}
+		defer releaseLock(logger, cacheLock)
+	}
+
+	switch args[0] {
+	case "list":
+		return cacheList(downloadDir, args[1:])
+	case "prune":
+		return cachePrune(logger, downloadDir, args[1:])
+	case "clean":
+		return cacheClean(logger, downloadDir, args[1:])
+	case "path":
+		if len(args) > 1 {
+			return fmt.Errorf("%w: %s", ErrUnexpectedArg, args[1])
+		}
+		fmt.Println(downloadDir)
+		return nil
+	case "-h", "-help", "--help", "help":
+		fmt.Println(cacheUsage)
+		return nil
+	default:
+		return fmt.Errorf("%w: cache %s\n%s", ErrUnknownCommand, args[0], cacheUsage)
+	}
+}
+
+func cacheList(downloadDir string, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnexpectedArg, args[0])
+	}
+
+	installs, err := fs.ListInstalls(downloadDir)
+	if err != nil {
+		return fmt.Errorf("failed to list installed versions: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tPLATFORM\tSIZE\tLAST USED")
+	for _, install := range installs {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", install.Version, platform(install), progress.FormatBytes(install.Size), install.LastUsed.Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+// platform returns the platform of the release asset the install was downloaded from, e.g.
+// linux-x64, or unknown when it was installed before the asset was recorded
+func platform(install fs.Install) string {
+	if install.Asset == "" {
+		return "unknown"
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(install.Asset, fs.PrefixTailwind), ".exe")
+}
+
+func cachePrune(logger *slog.Logger, downloadDir string, args []string) error {
+	flags := flag.NewFlagSet("prune", flag.ContinueOnError)
+	keep := flags.Int("keep", 1, "number of most recently used versions to keep")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("%w: %s", ErrUnexpectedArg, flags.Arg(0))
+	}
+
+	removed, err := fs.Prune(logger, downloadDir, *keep)
+	if err != nil {
+		return fmt.Errorf("failed to prune cache: %w", err)
+	}
+	printRemoved(removed)
+	return nil
+}
+
+func cacheClean(logger *slog.Logger, downloadDir string, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnexpectedArg, args[0])
+	}
+
+	removed, err := fs.Prune(logger, downloadDir, 0)
+	if err != nil {
+		return fmt.Errorf("failed to clean cache: %w", err)
+	}
+	if err = os.Remove(filepath.Join(downloadDir, client.LatestCacheFileName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clean cache: %w", err)
+	}
+	if err = fs.DeletePartials(logger, downloadDir); err != nil {
+		return fmt.Errorf("failed to clean cache: %w", err)
+	}
+	printRemoved(removed)
+	return nil
+}
+
+// releaseLock releases the cache lock, logging rather than failing since the lock is advisory
+func releaseLock(logger *slog.Logger, l *fs.Lock) {
+	if err := l.Release(); err != nil {
+		logger.Warn("Failed to release cache lock", "error", err)
+	}
+}
+
+func printRemoved(removed []fs.Install) {
+	var freed int64
+	for _, install := range removed {
+		fmt.Println("Deleted tailwindcss " + install.Version)
+		freed += install.Size
+	}
+	fmt.Printf("Freed %s\n", progress.FormatBytes(freed))
+}
@@ -0,0 +1,110 @@
+package main_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	main "github.com/Piszmog/go-tw"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cacheConfig returns a configuration whose cache directory has the versions installed, the first
+// most recently used
+func cacheConfig(t *testing.T, versions ...string) config.Config {
+	t.Helper()
+	cfg := config.Default()
+	cfg.CacheDir = t.TempDir()
+	now := time.Now()
+	for i, version := range versions {
+		path := filepath.Join(cfg.CacheDir, fs.PrefixTailwind+version)
+		require.NoError(t, os.WriteFile(path, []byte("binary"), 0600))
+		require.NoError(t, os.Chtimes(path, now, now.Add(-time.Duration(i)*time.Hour)))
+	}
+	return cfg
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name string
+			args []string
+			err  error
+		}{
+			{"Unknown command", []string{"purge"}, main.ErrUnknownCommand},
+			{"List with argument", []string{"list", "v4.0.0"}, main.ErrUnexpectedArg},
+			{"Prune with argument", []string{"prune", "v4.0.0"}, main.ErrUnexpectedArg},
+			{"Prune with argument after flag", []string{"prune", "-keep", "2", "v4.0.0"}, main.ErrUnexpectedArg},
+			{"Clean with argument", []string{"clean", "v4.0.0"}, main.ErrUnexpectedArg},
+			{"Path with argument", []string{"path", "v4.0.0"}, main.ErrUnexpectedArg},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+				cfg := cacheConfig(t, "v4.1.0", "v4.0.0")
+
+				err := main.Cache(slog.New(slog.DiscardHandler), cfg, tt.args)
+
+				require.ErrorIs(t, err, tt.err)
+				installs, listErr := fs.ListInstalls(cfg.CacheDir)
+				require.NoError(t, listErr)
+				assert.Len(t, installs, 2, "nothing is deleted")
+			})
+		}
+	})
+
+	t.Run("Prune with invalid keep", func(t *testing.T) {
+		t.Parallel()
+		cfg := cacheConfig(t, "v4.1.0", "v4.0.0")
+
+		err := main.Cache(slog.New(slog.DiscardHandler), cfg, []string{"prune", "-keep", "all"})
+
+		require.Error(t, err)
+		installs, listErr := fs.ListInstalls(cfg.CacheDir)
+		require.NoError(t, listErr)
+		assert.Len(t, installs, 2)
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		t.Parallel()
+		cfg := cacheConfig(t, "v4.1.0", "v4.0.0", "v3.4.17")
+
+		require.NoError(t, main.Cache(slog.New(slog.DiscardHandler), cfg, []string{"prune", "-keep", "2"}))
+
+		installs, err := fs.ListInstalls(cfg.CacheDir)
+		require.NoError(t, err)
+		require.Len(t, installs, 2)
+		assert.Equal(t, "v4.1.0", installs[0].Version)
+		assert.Equal(t, "v4.0.0", installs[1].Version)
+	})
+
+	t.Run("Clean", func(t *testing.T) {
+		t.Parallel()
+		cfg := cacheConfig(t, "v4.1.0", "v4.0.0")
+
+		require.NoError(t, main.Cache(slog.New(slog.DiscardHandler), cfg, []string{"clean"}))
+
+		installs, err := fs.ListInstalls(cfg.CacheDir)
+		require.NoError(t, err)
+		assert.Empty(t, installs)
+	})
+
+	t.Run("List, path and usage", func(t *testing.T) {
+		t.Parallel()
+		cfg := cacheConfig(t, "v4.1.0")
+
+		for _, args := range [][]string{{"list"}, {"path"}, {"help"}, nil} {
+			require.NoError(t, main.Cache(slog.New(slog.DiscardHandler), cfg, args), args)
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/diff"
+	"github.com/Piszmog/go-tw/tailwind"
+)
+
+var ErrStale = errors.New("committed output is stale")
+
+// maxDiffLines is the number of lines of the diff printed per file
+const maxDiffLines = 200
+
+const checkUsage = `Usage:
+  go-tw check [-j N] [target...]   Build the targets to a temp directory and fail if they differ from the committed outputs`
+
+// checkResult is the outcome of comparing a built file with the committed one
+type checkResult struct {
+	name string
+	// status is "up to date", "stale", "missing" or "failed"
+	status string
+	// diff is the unified diff from the committed to the built file, or why the build failed
+	diff string
+	// inserted and deleted are the number of lines changed
+	inserted, deleted int
+}
+
+// check builds the targets of the configuration file into a temp directory with the same
+// arguments and compares the result with the committed outputs, failing if any differs
+func check(ctx context.Context, opts tailwind.Options, cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	concurrency := flags.Int("j", cfg.Concurrency, "number of targets built at the same time, the number of CPUs when 0")
+	flags.Usage = func() { fmt.Println(checkUsage) }
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	targets, err := selectTargets(cfg.Targets, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp("", "go-tw-check-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+
+	// Every target is built to its own directory so outputs with the same name do not clash
+	tmpTargets := make([]tailwind.Target, len(targets))
+	for i, target := range targets {
+		dir := filepath.Join(tmp, target.Name)
+		if err = os.Mkdir(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		tmpTargets[i] = target
+		tmpTargets[i].Output = filepath.Join(dir, filepath.Base(target.Output))
+	}
+	results, err := tailwind.BuildTargets(ctx, tailwind.TargetsOptions{
+		Options:     opts,
+		Targets:     tmpTargets,
+		Dir:         cfg.Dir(),
+		Concurrency: *concurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	checked := make([]checkResult, 0, len(results)+1)
+	for i, result := range results {
+		checked = append(checked, checkTarget(cfg, targets[i], result))
+	}
+	if cfg.Codegen != nil && len(flags.Args()) == 0 && allUpToDate(checked) {
+		checked = append(checked, checkGo(cfg))
+	}
+
+	printChecked(checked)
+
+	var stale int
+	for _, result := range checked {
+		if result.status != "up to date" {
+			stale++
+		}
+	}
+	if stale > 0 {
+		return fmt.Errorf("%w: %d of %d outputs are not up to date, run go-tw build and commit the result", ErrStale, stale, len(checked))
+	}
+	return nil
+}
+
+// checkTarget compares the temp build of the target with its committed output
+func checkTarget(cfg config.Config, target tailwind.Target, result tailwind.TargetResult) checkResult {
+	checked := checkResult{name: target.Name}
+	if result.Err != nil {
+		checked.status = "failed"
+		checked.diff = strings.TrimSpace(result.Err.Error() + "\n" + string(result.Output))
+		return checked
+	}
+
+	//nolint:gosec // G304: path is the temp output of the target
+	built, err := os.ReadFile(result.Target.Output)
+	if err != nil {
+		checked.status = "failed"
+		checked.diff = err.Error()
+		return checked
+	}
+
+	committedPath, err := outputPath(resolve(cfg, target.Output), cfg.Hash)
+	if err != nil {
+		checked.status = "failed"
+		checked.diff = err.Error()
+		return checked
+	}
+	return compare(checked, relPath(cfg.Dir(), committedPath), committedPath, built)
+}
+
+// checkGo compares the Go code generated for the committed outputs with the committed file
+func checkGo(cfg config.Config) checkResult {
+	checked := checkResult{name: "codegen"}
+	file, src, err := goSource(cfg, cfg.Hash)
+	if err != nil {
+		checked.status = "failed"
+		checked.diff = err.Error()
+		return checked
+	}
+	return compare(checked, relPath(cfg.Dir(), file), file, src)
+}
+
+// compare compares the expected content with the committed file at path, named name in the diff
+func compare(checked checkResult, name string, path string, expected []byte) checkResult {
+	//nolint:gosec // G304: path is a committed output of the project
+	committed, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			checked.status = "failed"
+			checked.diff = err.Error()
+			return checked
+		}
+		checked.status = "missing"
+	}
+
+	if bytes.Equal(committed, expected) {
+		checked.status = "up to date"
+		return checked
+	}
+	if checked.status == "" {
+		checked.status = "stale"
+	}
+	checked.diff = diff.Unified("committed/"+name, "built/"+name, committed, expected)
+	checked.inserted, checked.deleted = diff.Stat(committed, expected)
+	return checked
+}
+
+func allUpToDate(checked []checkResult) bool {
+	for _, result := range checked {
+		if result.status != "up to date" {
+			return false
+		}
+	}
+	return true
+}
+
+// printChecked prints a table of the results, followed by the diff of every output that differs
+func printChecked(checked []checkResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TARGET\tSTATUS\tCHANGES")
+	for _, result := range checked {
+		changes := ""
+		if result.inserted > 0 || result.deleted > 0 {
+			changes = fmt.Sprintf("+%d -%d", result.inserted, result.deleted)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", result.name, result.status, changes)
+	}
+	_ = w.Flush()
+
+	for _, result := range checked {
+		if result.diff == "" {
+			continue
+		}
+		fmt.Println()
+		lines := strings.SplitAfter(strings.TrimSuffix(result.diff, "\n"), "\n")
+		if len(lines) > maxDiffLines {
+			fmt.Print(strings.Join(lines[:maxDiffLines], ""))
+			fmt.Printf("\n... %d more lines\n", len(lines)-maxDiffLines)
+			continue
+		}
+		fmt.Println(strings.Join(lines, ""))
+	}
+}
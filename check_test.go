@@ -0,0 +1,146 @@
+package main_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	main "github.com/Piszmog/go-tw"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (p *project) check(args ...string) error {
+	return main.Check(context.Background(), p.opts, p.cfg, args)
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Up to date", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+		require.NoError(t, p.build())
+
+		require.NoError(t, p.check())
+	})
+
+	t.Run("Stale output", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+		writeFile(t, p.dir, "dist/app.css", ".grid{display:grid}")
+
+		err := p.check()
+
+		require.ErrorIs(t, err, main.ErrStale)
+		assert.Equal(t, 1, main.ExitCode(err))
+		content, readErr := os.ReadFile(filepath.Join(p.dir, "dist", "app.css"))
+		require.NoError(t, readErr)
+		assert.Equal(t, ".grid{display:grid}", string(content), "the committed output is left unchanged")
+	})
+
+	t.Run("Missing output", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+
+		require.ErrorIs(t, p.check(), main.ErrStale)
+	})
+
+	t.Run("Failed build", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+		require.NoError(t, p.build())
+		installScript(t, p.opts.CacheDir, "v4.0.0", "exit 1")
+
+		require.ErrorIs(t, p.check(), main.ErrStale)
+	})
+
+	t.Run("Hashed output resolved through the manifest", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+		p.cfg.Hash = true
+		require.NoError(t, p.build())
+		require.NoError(t, p.check())
+
+		require.NoError(t, os.WriteFile(p.hashedOutput(t), []byte(".grid{display:grid}"), 0600))
+
+		require.ErrorIs(t, p.check(), main.ErrStale)
+	})
+
+	t.Run("Generated Go", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+		p.cfg.Codegen = &config.Codegen{File: "dist/assets.go"}
+		require.NoError(t, p.build())
+		require.NoError(t, p.check())
+
+		writeFile(t, p.dir, "dist/assets.go", "package dist\n")
+
+		require.ErrorIs(t, p.check(), main.ErrStale)
+	})
+
+	t.Run("Generated Go is not checked for a subset of targets", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+		p.cfg.Codegen = &config.Codegen{File: "dist/assets.go"}
+		require.NoError(t, p.build())
+		writeFile(t, p.dir, "dist/assets.go", "package dist\n")
+
+		require.NoError(t, p.check("app"))
+	})
+
+	t.Run("Unknown target", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+
+		require.ErrorIs(t, p.check("admin"), main.ErrUnknownTarget)
+	})
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// committed is the content of the committed file, which is missing when empty
+		committed string
+		status    string
+		diff      bool
+	}{
+		{name: "Missing", status: "missing", diff: true},
+		{name: "Stale", committed: ".grid{display:grid}\n", status: "stale", diff: true},
+		{name: "Up to date", committed: ".flex{display:flex}\n", status: "up to date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "app.css")
+			if tt.committed != "" {
+				require.NoError(t, os.WriteFile(path, []byte(tt.committed), 0600))
+			}
+
+			status, diff := main.Compare(path, []byte(".flex{display:flex}\n"))
+
+			assert.Equal(t, tt.status, status)
+			if tt.diff {
+				assert.Contains(t, diff, "--- committed/app.css")
+				assert.Contains(t, diff, "+++ built/app.css")
+				assert.Contains(t, diff, "+.flex{display:flex}")
+			} else {
+				assert.Empty(t, diff)
+			}
+		})
+	}
+
+	t.Run("Unreadable", func(t *testing.T) {
+		t.Parallel()
+
+		status, diff := main.Compare(t.TempDir(), []byte(".flex{display:flex}\n"))
+
+		assert.Equal(t, "failed", status)
+		assert.NotEmpty(t, diff)
+	})
+}
@@ -1,14 +1,19 @@
 package client
 
 import (
+	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,16 +22,29 @@ import (
 
 const (
 	urlDownload      = "https://github.com/tailwindlabs/tailwindcss/releases/download"
-	urlLatestVersion = "https://api.github.com/repos/tailwindlabs/tailwindcss/releases/latest"
-	maxRetries       = 3
-	retryDelay       = 2 * time.Second
+	urlAPI           = "https://api.github.com"
+	pathReleases     = "/repos/tailwindlabs/tailwindcss/releases"
+	urlLatestVersion = urlAPI + pathReleases + "/latest"
+	urlReleases      = urlAPI + pathReleases
+	releasesPerPage  = 100
+	maxReleasePages  = 10
+	checksumFileName = "sha256sums.txt"
 )
 
 type Client struct {
 	logger           *slog.Logger
 	c                *http.Client
 	downloadURL      string
+	downloadTemplate string
 	latestVersionURL string
+	releasesURL      string
+	token            string
+	offline          bool
+	latestCachePath  string
+	latestCacheTTL   time.Duration
+	progress         Progress
+	retryAttempts    int
+	retryDelay       time.Duration
 }
 
 func New(logger *slog.Logger, timeout time.Duration) *Client {
@@ -34,44 +52,115 @@ func New(logger *slog.Logger, timeout time.Duration) *Client {
 		logger:           logger,
 		c:                &http.Client{Timeout: timeout},
 		downloadURL:      urlDownload,
+		downloadTemplate: DefaultDownloadTemplate,
 		latestVersionURL: urlLatestVersion,
+		releasesURL:      urlReleases,
+		retryAttempts:    DefaultRetryAttempts,
+		retryDelay:       defaultRetryDelay,
 	}
 }
 
+// DefaultDownloadTemplate is the layout of release assets on GitHub, which most mirrors replicate
+const DefaultDownloadTemplate = "{base}/{version}/{asset}"
+
+// Clone returns a copy of the client, so it can be configured without changing the original.
+// The copy shares the underlying HTTP client.
+func (c *Client) Clone() *Client {
+	clone := *c
+	return &clone
+}
+
+// WithDownloadURL sets the base URL tailwindcss releases are downloaded from, e.g. a mirror
+func (c *Client) WithDownloadURL(downloadURL string) *Client {
+	c.downloadURL = strings.TrimSuffix(downloadURL, "/")
+	return c
+}
+
+// WithDownloadTemplate sets the template used to build the URL of a release asset. The
+// placeholders {base}, {version} and {asset} are replaced with the download URL, the release
+// tag and the asset name, e.g. "{base}/tailwindcss/{version}/{asset}".
+func (c *Client) WithDownloadTemplate(template string) *Client {
+	c.downloadTemplate = template
+	return c
+}
+
+// WithAPIURL sets the base URL of the GitHub API releases are looked up with, e.g. the
+// API of a GitHub Enterprise Server such as "https://github.example.com/api/v3"
+func (c *Client) WithAPIURL(apiURL string) *Client {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	c.latestVersionURL = apiURL + pathReleases + "/latest"
+	c.releasesURL = apiURL + pathReleases
+	return c
+}
+
+// assetURL builds the URL of the release asset from the download template
+func (c *Client) assetURL(version string, asset string) string {
+	return strings.NewReplacer(
+		"{base}", c.downloadURL,
+		"{version}", version,
+		"{asset}", asset,
+	).Replace(c.downloadTemplate)
+}
+
+// WithOffline prevents the client from making any network request. Every method fails
+// immediately with ErrOffline.
+func (c *Client) WithOffline(offline bool) *Client {
+	c.offline = offline
+	return c
+}
+
 // WithTestURLs allows injecting custom URLs for testing purposes
 func (c *Client) WithTestURLs(downloadURL, latestVersionURL string) *Client {
 	c.downloadURL = downloadURL
 	c.latestVersionURL = latestVersionURL
+	c.releasesURL = strings.TrimSuffix(latestVersionURL, "/latest")
 	return c
 }
 
-func (c *Client) Download(ctx context.Context, operatingSystem string, arch string, version string, path string, downloadDir string) error {
-	fileName := GetName(operatingSystem, arch)
-	url := c.downloadURL + "/" + version + "/" + fileName
+// Progress is notified of the bytes written while downloading tailwindcss
+type Progress interface {
+	io.Writer
+	// Start is called when the download starts, resuming after offset bytes. The total is
+	// negative when unknown.
+	Start(offset int64, total int64)
+	// Finish is called when the download completed.
+	Finish()
+	// Abort is called when the download failed.
+	Abort()
+}
 
-	var lastErr error
+// WithProgress reports the progress of downloads to p
+func (c *Client) WithProgress(p Progress) *Client {
+	c.progress = p
+	return c
+}
 
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if attempt > 1 {
-			c.logger.Info("Download failed, retrying", "attempt", attempt, "max", maxRetries)
-			time.Sleep(retryDelay)
-		}
+// Download retrieves the tailwindcss binary for the platform and verifies it against checksum.
+// When checksum is empty, the digest is looked up in the release's checksum manifest.
+func (c *Client) Download(ctx context.Context, operatingSystem string, arch string, version string, path string, downloadDir string, checksum string) error {
+	fileName := GetName(operatingSystem, arch)
+	url := c.assetURL(version, fileName)
 
-		err := c.downloadAttempt(ctx, url, path, downloadDir)
-		if err == nil {
-			return nil // Success!
+	if checksum == "" {
+		var err error
+		checksum, err = c.GetChecksum(ctx, version, fileName)
+		if err != nil {
+			return fmt.Errorf("failed to get checksum for %s: %w", fileName, err)
 		}
+	}
 
-		lastErr = err
-		c.logger.Info("Download attempt failed", "attempt", attempt, "error", err)
-
-		// Clean up partial file
-		if removeErr := os.Remove(path); removeErr != nil && !os.IsNotExist(removeErr) {
-			c.logger.Error("Failed to clean up partial download", "path", path, "error", removeErr)
-		}
+	err := c.retry(ctx, "Download", func() error {
+		return c.downloadAttempt(ctx, url, path, downloadDir, checksum)
+	})
+	if err == nil {
+		return nil
 	}
 
-	return fmt.Errorf("%w: %w", ErrDownloadFailed, lastErr)
+	// A digest mismatch means the content itself is wrong, downloading it again will not help
+	if errors.Is(err, fs.ErrChecksumMismatch) || errors.Is(err, ErrOffline) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrDownloadFailed, err)
 }
 
 // FileReader is an interface for reading file contents and checking file existence
@@ -125,8 +214,13 @@ func GetName(os string, arch string) string {
 // GetNameWithReader generates the tailwindcss binary filename, using the provided FileReader
 // for musl detection. Useful for testing.
 func GetNameWithReader(os string, arch string, reader FileReader) string {
+	return GetAssetName(os, arch, os == "linux" && isMusl(reader))
+}
+
+// GetAssetName generates the tailwindcss binary filename for the given OS, architecture and libc
+func GetAssetName(os string, arch string, musl bool) string {
 	muslPostfix := ""
-	if os == "linux" && isMusl(reader) {
+	if os == "linux" && musl {
 		muslPostfix = "-musl"
 	}
 
@@ -148,39 +242,245 @@ func GetNameWithReader(os string, arch string, reader FileReader) string {
 	return "tailwindcss-" + osName + "-" + archName + muslPostfix + executablePostfix
 }
 
+// GetAssetNames returns the tailwindcss binary filenames for every supported platform
+func GetAssetNames() []string {
+	var names []string
+	for _, os := range []string{"darwin", "linux", "windows"} {
+		for _, arch := range []string{"amd64", "arm64"} {
+			names = append(names, GetAssetName(os, arch, false))
+			if os == "linux" {
+				names = append(names, GetAssetName(os, arch, true))
+			}
+		}
+	}
+	return names
+}
+
+// GetLatestVersion retrieves the tag of the latest tailwindcss release, using the cached
+// version when the client is configured with WithLatestCache
 func (c *Client) GetLatestVersion(ctx context.Context) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.latestVersionURL, nil)
+	if c.offline {
+		return "", ErrOffline
+	}
+
+	cached := c.readLatestCache()
+	now := time.Now()
+	if cached.fresh(c.latestCacheTTL, now) {
+		c.logger.Debug("Using cached latest version", "version", cached.Version, "checkedAt", cached.CheckedAt)
+		return cached.Version, nil
+	}
+
+	var version string
+	err := c.retry(ctx, "Latest version lookup", func() error {
+		var fetchErr error
+		version, fetchErr = c.fetchLatestVersion(ctx, cached, now)
+		return fetchErr
+	})
+	return version, err
+}
+
+// fetchLatestVersion asks the GitHub API for the latest release, revalidating the cached version
+func (c *Client) fetchLatestVersion(ctx context.Context, cached latestCache, now time.Time) (string, error) {
+	req, err := c.newAPIRequest(ctx, c.latestVersionURL)
 	if err != nil {
 		return "", err
 	}
+	if cached.Version != "" && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
 
-	resp, err := c.c.Do(req) //nolint:gosec // G704: URL is a hardcoded GitHub API constant, not user input
+	resp, err := c.do(req)
 	if err != nil {
-		return "", ErrHTTP
+		return "", httpError(err)
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
-			c.logger.Error("failed to close body", "error", err)
+			c.logger.Error("failed to close body", "error", closeErr)
 		}
 	}()
 
+	if resp.StatusCode == http.StatusNotModified && cached.Version != "" {
+		c.logger.Debug("Latest version unchanged", "version", cached.Version)
+		cached.CheckedAt = now
+		c.writeLatestCache(cached)
+		return cached.Version, nil
+	}
+
+	if err = c.checkAPIResponse(resp); err != nil {
+		return "", err
+	}
+
 	var release release
 	if err = json.NewDecoder(resp.Body).Decode(&release); err != nil {
 		return "", err
 	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("%w: latest release has no tag name", ErrInvalidRelease)
+	}
 
+	c.writeLatestCache(latestCache{
+		Version:   release.TagName,
+		ETag:      resp.Header.Get("ETag"),
+		CheckedAt: now,
+	})
 	return release.TagName, nil
 }
 
-func (c *Client) downloadAttempt(ctx context.Context, url string, path string, downloadDir string) error {
-	c.logger.Debug("Downloading file", "url", url)
+// GetChecksum retrieves the SHA-256 digest published in the release's checksum manifest
+// for the given asset
+func (c *Client) GetChecksum(ctx context.Context, version string, fileName string) (string, error) {
+	checksums, err := c.GetChecksums(ctx, version)
+	if err != nil {
+		return "", err
+	}
+
+	checksum, ok := checksums[fileName]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrChecksumNotFound, fileName)
+	}
+	return checksum, nil
+}
+
+// GetChecksums retrieves the checksum manifest published with the release, keyed by asset name
+func (c *Client) GetChecksums(ctx context.Context, version string) (map[string]string, error) {
+	var checksums map[string]string
+	err := c.retry(ctx, "Checksum download", func() error {
+		var fetchErr error
+		checksums, fetchErr = c.fetchChecksums(ctx, version)
+		return fetchErr
+	})
+	return checksums, err
+}
+
+func (c *Client) fetchChecksums(ctx context.Context, version string) (map[string]string, error) {
+	url := c.assetURL(version, checksumFileName)
+	c.logger.Debug("Downloading checksums", "url", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, httpError(err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			c.logger.Error("failed to close body", "error", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Error("failed to download checksums", "status_code", resp.StatusCode)
+		return nil, newStatusError(resp, fmt.Errorf("%w: unexpected status %d", ErrHTTP, resp.StatusCode))
+	}
+
+	return parseChecksums(resp.Body)
+}
+
+// parseChecksums parses a sha256sum formatted manifest, where each line is the hex digest
+// followed by the file name, e.g. "<digest>  ./tailwindcss-linux-x64"
+func parseChecksums(r io.Reader) (map[string]string, error) {
+	checksums := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || !isSHA256(fields[0]) {
+			continue
+		}
+		// sha256sum marks binary mode with a leading '*'
+		name := strings.TrimPrefix(fields[1], "*")
+		name = strings.TrimPrefix(name, "./")
+		checksums[name] = strings.ToLower(fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(checksums) == 0 {
+		return nil, ErrInvalidChecksums
+	}
+	return checksums, nil
+}
+
+func isSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// ListVersions retrieves the tags of every published (non-draft) tailwindcss release
+func (c *Client) ListVersions(ctx context.Context) ([]string, error) {
+	var versions []string
+	for page := 1; page <= maxReleasePages; page++ {
+		releases, err := c.getReleases(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range releases {
+			if !r.Draft && r.TagName != "" {
+				versions = append(versions, r.TagName)
+			}
+		}
+
+		if len(releases) < releasesPerPage {
+			break
+		}
+	}
+	return versions, nil
+}
+
+func (c *Client) getReleases(ctx context.Context, page int) ([]release, error) {
+	url := c.releasesURL + "?per_page=" + strconv.Itoa(releasesPerPage) + "&page=" + strconv.Itoa(page)
+	c.logger.Debug("Listing releases", "url", url)
+
+	req, err := c.newAPIRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, httpError(err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			c.logger.Error("failed to close body", "error", closeErr)
+		}
+	}()
+
+	if err = c.checkAPIResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var releases []release
+	if err = json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// downloadAttempt downloads the file, resuming an earlier attempt with a Range request when the
+// server supports it. A partial download is kept when the attempt fails so the next one can resume.
+func (c *Client) downloadAttempt(ctx context.Context, url string, path string, downloadDir string, checksum string) error {
+	partial := fs.ReadPartial(c.logger, path, url)
+	c.logger.Debug("Downloading file", "url", url, "offset", partial.Received)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return err
 	}
+	if partial.Received > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(partial.Received, 10)+"-")
+		req.Header.Set("If-Range", partial.Validator())
+	}
 
-	resp, err := c.c.Do(req) //nolint:gosec // G704: URL is derived from a hardcoded GitHub releases constant, not user input
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -190,19 +490,100 @@ func (c *Client) downloadAttempt(ctx context.Context, url string, path string, d
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
+	// Pass the full size for size validation
+	var expectedSize int64
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var start int64
+		start, expectedSize, err = parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != partial.Received {
+			fs.DeletePartial(c.logger, path)
+			return fmt.Errorf("%w: unexpected Content-Range %q", ErrHTTP, resp.Header.Get("Content-Range"))
+		}
+		c.logger.Info("Resuming download", "offset", partial.Received, "size", expectedSize)
+	case http.StatusOK:
+		// The server ignored the range or the file changed, start over
+		partial = fs.Partial{
+			URL:          url,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+		expectedSize = resp.ContentLength
+	case http.StatusRequestedRangeNotSatisfiable:
+		fs.DeletePartial(c.logger, path)
+		return fmt.Errorf("%w: range not satisfiable", ErrHTTP)
+	default:
 		c.logger.Error("failed to download file", "status_code", resp.StatusCode)
-		return ErrHTTP
+		return newStatusError(resp, fmt.Errorf("%w: unexpected status %d", ErrHTTP, resp.StatusCode))
+	}
+
+	if c.progress == nil {
+		return fs.WritePartial(c.logger, resp.Body, path, downloadDir, partial, expectedSize, checksum, nil)
+	}
+
+	c.progress.Start(partial.Received, expectedSize)
+	if err = fs.WritePartial(c.logger, resp.Body, path, downloadDir, partial, expectedSize, checksum, c.progress); err != nil {
+		c.progress.Abort()
+		return err
+	}
+	c.progress.Finish()
+	return nil
+}
+
+// parseContentRange parses a Content-Range header such as "bytes 100-199/200", returning the
+// start of the range and the full size, which is -1 when unknown
+func parseContentRange(header string) (int64, int64, error) {
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, ErrInvalidContentRange
+	}
+	byteRange, size, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, ErrInvalidContentRange
+	}
+	first, _, found := strings.Cut(byteRange, "-")
+	if !found {
+		return 0, 0, ErrInvalidContentRange
 	}
 
-	// Pass Content-Length for size validation
-	expectedSize := resp.ContentLength
-	return fs.Write(c.logger, resp.Body, path, downloadDir, expectedSize)
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidContentRange
+	}
+	if size == "*" {
+		return start, -1, nil
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidContentRange
+	}
+	return start, total, nil
+}
+
+// do sends the request, failing without touching the network when the client is offline
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.offline {
+		return nil, fmt.Errorf("%w: %s", ErrOffline, req.URL)
+	}
+	return c.c.Do(req) //nolint:gosec // G704: URL is derived from the configured tailwindcss release URLs
+}
+
+// httpError maps a failed request to ErrHTTP, unless the request was never sent because the client is offline
+func httpError(err error) error {
+	if errors.Is(err, ErrOffline) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrHTTP, err)
 }
 
 var ErrHTTP = errors.New("failed to get the resource")
+var ErrInvalidContentRange = errors.New("invalid Content-Range header")
+var ErrOffline = errors.New("offline mode, network access is disabled")
 var ErrDownloadFailed = errors.New("failed to download after multiple attempts")
+var ErrChecksumNotFound = errors.New("no checksum published for asset")
+var ErrInvalidChecksums = errors.New("invalid checksum manifest")
 
 type release struct {
 	TagName string `json:"tag_name"`
+	Draft   bool   `json:"draft"`
 }
@@ -1,7 +1,10 @@
 package client_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"log/slog"
 	"net/http"
@@ -14,6 +17,7 @@ import (
 	"time"
 
 	"github.com/Piszmog/go-tw/client"
+	"github.com/Piszmog/go-tw/fs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,6 +27,12 @@ func testLogger() *slog.Logger {
 	return slog.New(slog.DiscardHandler)
 }
 
+// checksums builds a sha256sums.txt manifest containing the digest of content for name
+func checksums(name string, content []byte) []byte {
+	sum := sha256.Sum256(content)
+	return []byte(hex.EncodeToString(sum[:]) + "  ./" + name + "\n")
+}
+
 // isMuslEnvironment mirrors the production musl detection logic so TestGetName
 // can compute the correct expected value for Linux regardless of whether the
 // test runs on a glibc or musl host.
@@ -73,6 +83,20 @@ func TestGetName(t *testing.T) {
 	}
 }
 
+func TestGetAssetNames(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{
+		"tailwindcss-macos-x64",
+		"tailwindcss-macos-arm64",
+		"tailwindcss-linux-x64",
+		"tailwindcss-linux-x64-musl",
+		"tailwindcss-linux-arm64",
+		"tailwindcss-linux-arm64-musl",
+		"tailwindcss-windows-x64.exe",
+		"tailwindcss-windows-arm64.exe",
+	}, client.GetAssetNames())
+}
+
 func TestNew(t *testing.T) {
 	t.Parallel()
 	logger := testLogger()
@@ -95,7 +119,7 @@ func TestGetLatestVersion(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := client.New(testLogger(), 30*time.Second).WithTestURLs("", server.URL)
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL)
 
 		version, err := c.GetLatestVersion(context.Background())
 
@@ -110,7 +134,7 @@ func TestGetLatestVersion(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := client.New(testLogger(), 30*time.Second).WithTestURLs("", server.URL)
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL)
 
 		_, err := c.GetLatestVersion(context.Background())
 
@@ -125,7 +149,7 @@ func TestGetLatestVersion(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := client.New(testLogger(), 30*time.Second).WithTestURLs("", server.URL)
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL)
 
 		_, err := c.GetLatestVersion(context.Background())
 
@@ -143,7 +167,7 @@ func TestGetLatestVersion(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // Cancel immediately
 
-		c := client.New(testLogger(), 30*time.Second).WithTestURLs("", server.URL)
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL)
 
 		_, err := c.GetLatestVersion(ctx)
 
@@ -151,6 +175,22 @@ func TestGetLatestVersion(t *testing.T) {
 	})
 }
 
+// fakeProgress records the progress reported by a download
+type fakeProgress struct {
+	total    int64
+	written  int64
+	finished bool
+}
+
+func (p *fakeProgress) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	return len(b), nil
+}
+
+func (p *fakeProgress) Start(_ int64, total int64) { p.total = total }
+func (p *fakeProgress) Finish()                    { p.finished = true }
+func (p *fakeProgress) Abort()                     {}
+
 func TestDownload(t *testing.T) {
 	t.Parallel()
 	t.Run("Successful download", func(t *testing.T) {
@@ -158,6 +198,10 @@ func TestDownload(t *testing.T) {
 		content := []byte("fake tailwindcss binary content here")
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/sha256sums.txt") {
+				_, _ = w.Write(checksums(client.GetName("linux", "amd64"), content))
+				return
+			}
 			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write(content)
@@ -167,9 +211,9 @@ func TestDownload(t *testing.T) {
 		tmpDir := t.TempDir()
 		filePath := filepath.Join(tmpDir, "tailwindcss-test")
 
-		c := client.New(testLogger(), 30*time.Second).WithTestURLs(server.URL, "")
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
 
-		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir)
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir, "")
 
 		require.NoError(t, err)
 
@@ -180,10 +224,38 @@ func TestDownload(t *testing.T) {
 		assert.Equal(t, content, written)
 	})
 
+	t.Run("Reports progress", func(t *testing.T) {
+		t.Parallel()
+		content := []byte("fake tailwindcss binary content here")
+		sum := sha256.Sum256(content)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-test")
+		p := &fakeProgress{}
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "").WithProgress(p)
+
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir, hex.EncodeToString(sum[:]))
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), p.total)
+		assert.Equal(t, int64(len(content)), p.written)
+		assert.True(t, p.finished)
+	})
+
 	t.Run("HTTP Error triggers retry", func(t *testing.T) {
 		t.Parallel()
 		attemptCount := 0
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/sha256sums.txt") {
+				_, _ = w.Write(checksums(client.GetName("linux", "amd64"), []byte("content")))
+				return
+			}
 			attemptCount++
 			w.WriteHeader(http.StatusInternalServerError)
 		}))
@@ -192,9 +264,9 @@ func TestDownload(t *testing.T) {
 		tmpDir := t.TempDir()
 		filePath := filepath.Join(tmpDir, "tailwindcss-test")
 
-		c := client.New(testLogger(), 30*time.Second).WithTestURLs(server.URL, "")
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
 
-		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir)
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir, "")
 
 		require.Error(t, err)
 		require.ErrorIs(t, err, client.ErrDownloadFailed)
@@ -216,9 +288,9 @@ func TestDownload(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // Cancel immediately
 
-		c := client.New(testLogger(), 30*time.Second).WithTestURLs(server.URL, "")
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
 
-		err := c.Download(ctx, "linux", "amd64", "v4.0.0", filePath, tmpDir)
+		err := c.Download(ctx, "linux", "amd64", "v4.0.0", filePath, tmpDir, "")
 
 		assert.Error(t, err)
 	})
@@ -228,6 +300,10 @@ func TestDownload(t *testing.T) {
 		content := []byte("fake content")
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/sha256sums.txt") {
+				_, _ = w.Write(checksums(client.GetName("linux", "amd64"), content))
+				return
+			}
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write(content)
 		}))
@@ -236,10 +312,355 @@ func TestDownload(t *testing.T) {
 		tmpDir := t.TempDir()
 		invalidPath := "/tmp/malicious.bin"
 
-		c := client.New(testLogger(), 30*time.Second).WithTestURLs(server.URL, "")
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
 
-		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", invalidPath, tmpDir)
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", invalidPath, tmpDir, "")
 
 		assert.Error(t, err)
 	})
 }
+
+func TestDownloadChecksum(t *testing.T) {
+	t.Parallel()
+	t.Run("Checksum mismatch is not retried", func(t *testing.T) {
+		t.Parallel()
+		attemptCount := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/sha256sums.txt") {
+				_, _ = w.Write(checksums(client.GetName("linux", "amd64"), []byte("original binary")))
+				return
+			}
+			attemptCount++
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("tampered binary"))
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-test")
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
+
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir, "")
+
+		require.ErrorIs(t, err, fs.ErrChecksumMismatch)
+		assert.Equal(t, 1, attemptCount)
+		assert.NoFileExists(t, filePath)
+	})
+
+	t.Run("Provided checksum skips manifest", func(t *testing.T) {
+		t.Parallel()
+		content := []byte("fake tailwindcss binary content here")
+		sum := sha256.Sum256(content)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/sha256sums.txt") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-test")
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
+
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir, hex.EncodeToString(sum[:]))
+
+		require.NoError(t, err)
+		assert.FileExists(t, filePath)
+	})
+
+	t.Run("Missing checksum manifest", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/sha256sums.txt") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte("binary"))
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-test")
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
+
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir, "")
+
+		require.ErrorIs(t, err, client.ErrHTTP)
+		assert.NoFileExists(t, filePath)
+	})
+}
+
+func TestDownloadResume(t *testing.T) {
+	t.Parallel()
+	content := []byte(strings.Repeat("fake tailwindcss binary content ", 100))
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	t.Run("Resumes interrupted download", func(t *testing.T) {
+		t.Parallel()
+		var ranges []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ranges = append(ranges, r.Header.Get("Range"))
+			if len(ranges) == 1 {
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				_, _ = w.Write(content[:1000])
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-test")
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
+
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir, checksum)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", "bytes=1000-"}, ranges)
+		//nolint:gosec // G304: Reading from test temp file, safe
+		written, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, content, written)
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "partial download should be cleaned up")
+	})
+
+	t.Run("Starts over when server ignores range", func(t *testing.T) {
+		t.Parallel()
+		attemptCount := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attemptCount++
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			if attemptCount == 1 {
+				_, _ = w.Write(content[:1000])
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-test")
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
+
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir, checksum)
+
+		require.NoError(t, err)
+		//nolint:gosec // G304: Reading from test temp file, safe
+		written, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, content, written)
+	})
+}
+
+func TestGetChecksums(t *testing.T) {
+	t.Parallel()
+	t.Run("Parses manifest", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v4.0.0/sha256sums.txt", r.URL.Path)
+			_, _ = w.Write([]byte(strings.Repeat("AB", 32) + "  ./tailwindcss-linux-x64\n" + strings.Repeat("cd", 32) + " *tailwindcss-windows-x64.exe\n\n"))
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
+
+		sums, err := c.GetChecksums(context.Background(), "v4.0.0")
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"tailwindcss-linux-x64":       strings.Repeat("ab", 32),
+			"tailwindcss-windows-x64.exe": strings.Repeat("cd", 32),
+		}, sums)
+	})
+
+	t.Run("Asset not listed", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(strings.Repeat("ab", 32) + "  ./tailwindcss-linux-x64\n"))
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
+
+		_, err := c.GetChecksum(context.Background(), "v4.0.0", "tailwindcss-macos-arm64")
+
+		assert.ErrorIs(t, err, client.ErrChecksumNotFound)
+	})
+
+	t.Run("Empty manifest", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("<html>not found</html>"))
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
+
+		_, err := c.GetChecksums(context.Background(), "v4.0.0")
+
+		assert.ErrorIs(t, err, client.ErrInvalidChecksums)
+	})
+}
+
+func TestListVersions(t *testing.T) {
+	t.Parallel()
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/releases", r.URL.Path)
+			assert.Equal(t, "1", r.URL.Query().Get("page"))
+			_, _ = w.Write([]byte(`[
+				{"tag_name": "v4.1.0", "draft": false},
+				{"tag_name": "v4.2.0", "draft": true},
+				{"tag_name": "v4.0.7", "draft": false}
+			]`))
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL+"/releases/latest")
+
+		versions, err := c.ListVersions(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v4.1.0", "v4.0.7"}, versions)
+	})
+
+	t.Run("Paginates", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				_, _ = w.Write([]byte(`[{"tag_name": "v0.0.0"}]`))
+				return
+			}
+			releases := make([]map[string]string, 100)
+			for i := range releases {
+				releases[i] = map[string]string{"tag_name": "v1.0." + strconv.Itoa(i)}
+			}
+			_ = json.NewEncoder(w).Encode(releases)
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL+"/releases/latest")
+
+		versions, err := c.ListVersions(context.Background())
+
+		require.NoError(t, err)
+		assert.Len(t, versions, 101)
+		assert.Equal(t, "v0.0.0", versions[100])
+	})
+
+	t.Run("HTTP Error", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL+"/releases/latest")
+
+		_, err := c.ListVersions(context.Background())
+
+		assert.ErrorIs(t, err, client.ErrHTTP)
+	})
+}
+
+func TestOffline(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, server.URL).WithOffline(true)
+	ctx := context.Background()
+
+	_, err := c.GetLatestVersion(ctx)
+	require.ErrorIs(t, err, client.ErrOffline)
+
+	_, err = c.ListVersions(ctx)
+	require.ErrorIs(t, err, client.ErrOffline)
+
+	_, err = c.GetChecksums(ctx, "v4.0.0")
+	require.ErrorIs(t, err, client.ErrOffline)
+
+	tmpDir := t.TempDir()
+	err = c.Download(ctx, "linux", "amd64", "v4.0.0", filepath.Join(tmpDir, "tailwindcss-test"), tmpDir, "abc")
+	require.ErrorIs(t, err, client.ErrOffline)
+
+	assert.Equal(t, 0, requests)
+}
+
+func TestMirrorURLs(t *testing.T) {
+	t.Parallel()
+	t.Run("Download template", func(t *testing.T) {
+		t.Parallel()
+		content := []byte("fake tailwindcss binary content here")
+		var paths []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			if strings.HasSuffix(r.URL.Path, "/sha256sums.txt") {
+				_, _ = w.Write(checksums(client.GetName("linux", "amd64"), content))
+				return
+			}
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-test")
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).
+			WithDownloadURL(server.URL + "/artifactory/").
+			WithDownloadTemplate("{base}/tailwindcss/{version}/bin/{asset}")
+
+		err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filePath, tmpDir, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"/artifactory/tailwindcss/v4.0.0/bin/sha256sums.txt",
+			"/artifactory/tailwindcss/v4.0.0/bin/" + client.GetName("linux", "amd64"),
+		}, paths)
+	})
+
+	t.Run("API URL", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v3/repos/tailwindlabs/tailwindcss/releases/latest":
+				_, _ = w.Write([]byte(`{"tag_name": "v4.1.0"}`))
+			case "/api/v3/repos/tailwindlabs/tailwindcss/releases":
+				_, _ = w.Write([]byte(`[{"tag_name": "v4.1.0"}, {"tag_name": "v4.0.0"}]`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithAPIURL(server.URL + "/api/v3/")
+
+		version, err := c.GetLatestVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "v4.1.0", version)
+
+		versions, err := c.ListVersions(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v4.1.0", "v4.0.0"}, versions)
+	})
+}
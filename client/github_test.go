@@ -0,0 +1,120 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/go-tw/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToken(t *testing.T) {
+	t.Parallel()
+	content := []byte("fake tailwindcss binary content here")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/latest"):
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"tag_name": "v4.0.0"}`))
+		case strings.HasSuffix(r.URL.Path, "/sha256sums.txt"):
+			assert.Empty(t, r.Header.Get("Authorization"))
+			_, _ = w.Write(checksums(client.GetName("linux", "amd64"), content))
+		default:
+			assert.Empty(t, r.Header.Get("Authorization"))
+			_, _ = w.Write(content)
+		}
+	}))
+	defer server.Close()
+
+	c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, server.URL+"/latest").WithToken("secret")
+
+	version, err := c.GetLatestVersion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v4.0.0", version)
+
+	tmpDir := t.TempDir()
+	err = c.Download(context.Background(), "linux", "amd64", version, filepath.Join(tmpDir, "tailwindcss-test"), tmpDir, "")
+	require.NoError(t, err)
+}
+
+func TestGitHubAPIErrors(t *testing.T) {
+	t.Parallel()
+
+	reset := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		body    string
+		wantErr error
+	}{
+		{
+			name:   "Primary rate limit",
+			status: http.StatusForbidden,
+			headers: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+			},
+			body:    `{"message": "API rate limit exceeded"}`,
+			wantErr: client.ErrRateLimited,
+		},
+		{
+			name:    "Secondary rate limit",
+			status:  http.StatusForbidden,
+			headers: map[string]string{"Retry-After": "60"},
+			wantErr: client.ErrRateLimited,
+		},
+		{
+			name:    "Too many requests",
+			status:  http.StatusTooManyRequests,
+			wantErr: client.ErrRateLimited,
+		},
+		{
+			name:    "Forbidden",
+			status:  http.StatusForbidden,
+			headers: map[string]string{"X-RateLimit-Remaining": "42"},
+			body:    `{"message": "Forbidden"}`,
+			wantErr: client.ErrHTTP,
+		},
+		{
+			name:    "Not found",
+			status:  http.StatusNotFound,
+			body:    `{"message": "Not Found"}`,
+			wantErr: client.ErrHTTP,
+		},
+		{
+			name:    "Empty tag name",
+			status:  http.StatusOK,
+			body:    `{"message": "something else"}`,
+			wantErr: client.ErrInvalidRelease,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL)
+
+			version, err := c.GetLatestVersion(context.Background())
+
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, version)
+		})
+	}
+}
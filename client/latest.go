@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LatestCacheFileName is the name of the file the latest version lookup is cached in
+const LatestCacheFileName = "latest.json"
+
+// latestCache is the result of the last latest version lookup
+type latestCache struct {
+	Version   string    `json:"version"`
+	ETag      string    `json:"etag"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// WithLatestCache caches the latest version in dir, reusing it for ttl before asking the
+// GitHub API again. Once expired, the lookup is revalidated with the cached ETag, so an
+// unchanged release is a cheap 304 response.
+func (c *Client) WithLatestCache(dir string, ttl time.Duration) *Client {
+	c.latestCachePath = filepath.Join(dir, LatestCacheFileName)
+	c.latestCacheTTL = ttl
+	return c
+}
+
+// fresh reports whether the cached version can be used without asking the GitHub API
+func (l latestCache) fresh(ttl time.Duration, now time.Time) bool {
+	if l.Version == "" || l.CheckedAt.After(now) {
+		return false
+	}
+	return now.Sub(l.CheckedAt) < ttl
+}
+
+// readLatestCache reads the cached latest version. A missing or corrupt cache is
+// treated as empty, it is only an optimization.
+func (c *Client) readLatestCache() latestCache {
+	if c.latestCachePath == "" {
+		return latestCache{}
+	}
+
+	data, err := os.ReadFile(c.latestCachePath) //nolint:gosec // G304: path is in the download directory
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.logger.Debug("Failed to read latest version cache", "path", c.latestCachePath, "error", err)
+		}
+		return latestCache{}
+	}
+
+	var cached latestCache
+	if err = json.Unmarshal(data, &cached); err != nil {
+		c.logger.Debug("Ignoring invalid latest version cache", "path", c.latestCachePath, "error", err)
+		return latestCache{}
+	}
+	return cached
+}
+
+func (c *Client) writeLatestCache(cached latestCache) {
+	if c.latestCachePath == "" {
+		return
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		c.logger.Debug("Failed to encode latest version cache", "error", err)
+		return
+	}
+	if err = os.WriteFile(c.latestCachePath, data, 0600); err != nil {
+		c.logger.Debug("Failed to write latest version cache", "path", c.latestCachePath, "error", err)
+	}
+}
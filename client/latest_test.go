@@ -0,0 +1,143 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/go-tw/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLatestCache writes a latest version cache file to dir
+func writeLatestCache(t *testing.T, dir string, version string, etag string, checkedAt time.Time) {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"version": version, "etag": etag, "checked_at": checkedAt})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, client.LatestCacheFileName), data, 0600))
+}
+
+// readLatestCache reads the latest version cache file from dir
+func readLatestCache(t *testing.T, dir string) map[string]any {
+	t.Helper()
+	//nolint:gosec // G304: Reading from test temp file, safe
+	data, err := os.ReadFile(filepath.Join(dir, client.LatestCacheFileName))
+	require.NoError(t, err)
+	var cached map[string]any
+	require.NoError(t, json.Unmarshal(data, &cached))
+	return cached
+}
+
+func TestLatestCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Caches response with ETag", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"abc"`)
+			_, _ = w.Write([]byte(`{"tag_name": "v4.1.0"}`))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL).WithLatestCache(dir, time.Hour)
+
+		version, err := c.GetLatestVersion(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.1.0", version)
+		cached := readLatestCache(t, dir)
+		assert.Equal(t, "v4.1.0", cached["version"])
+		assert.Equal(t, `"abc"`, cached["etag"])
+	})
+
+	t.Run("Fresh cache skips request", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			_, _ = w.Write([]byte(`{"tag_name": "v4.2.0"}`))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		writeLatestCache(t, dir, "v4.1.0", `"abc"`, time.Now().Add(-time.Minute))
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL).WithLatestCache(dir, time.Hour)
+
+		version, err := c.GetLatestVersion(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.1.0", version)
+		assert.Equal(t, int32(0), requests.Load())
+	})
+
+	t.Run("Expired cache revalidates with ETag", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == `"abc"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = w.Write([]byte(`{"tag_name": "v4.2.0"}`))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		checkedAt := time.Now().Add(-48 * time.Hour)
+		writeLatestCache(t, dir, "v4.1.0", `"abc"`, checkedAt)
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL).WithLatestCache(dir, 24*time.Hour)
+
+		version, err := c.GetLatestVersion(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.1.0", version)
+		cached := readLatestCache(t, dir)
+		newCheckedAt, err := time.Parse(time.RFC3339Nano, cached["checked_at"].(string))
+		require.NoError(t, err)
+		assert.True(t, newCheckedAt.After(checkedAt))
+	})
+
+	t.Run("Expired cache updated with new release", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"def"`)
+			_, _ = w.Write([]byte(`{"tag_name": "v4.2.0"}`))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		writeLatestCache(t, dir, "v4.1.0", `"abc"`, time.Now().Add(-48*time.Hour))
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL).WithLatestCache(dir, 24*time.Hour)
+
+		version, err := c.GetLatestVersion(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.2.0", version)
+		assert.Equal(t, `"def"`, readLatestCache(t, dir)["etag"])
+	})
+
+	t.Run("Corrupt cache is ignored", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.Header.Get("If-None-Match"))
+			_, _ = w.Write([]byte(`{"tag_name": "v4.2.0"}`))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, client.LatestCacheFileName), []byte("{"), 0600))
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL).WithLatestCache(dir, 24*time.Hour)
+
+		version, err := c.GetLatestVersion(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.2.0", version)
+	})
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Piszmog/go-tw/fs"
+)
+
+const (
+	// DefaultRetryAttempts is how many times a request is attempted before giving up
+	DefaultRetryAttempts = 3
+	// defaultRetryDelay is the delay before the first retry, doubling with every attempt
+	defaultRetryDelay = time.Second
+	// maxRetryDelay caps the backoff. A server asking to wait longer than this is not retried,
+	// failing fast is more useful than hanging a build.
+	maxRetryDelay = 30 * time.Second
+)
+
+// WithRetryAttempts sets how many times a request is attempted before giving up
+func (c *Client) WithRetryAttempts(attempts int) *Client {
+	c.retryAttempts = max(attempts, 1)
+	return c
+}
+
+// WithRetryDelay sets the delay before the first retry, which doubles with every attempt
+func (c *Client) WithRetryDelay(delay time.Duration) *Client {
+	c.retryDelay = delay
+	return c
+}
+
+// StatusError is an unexpected HTTP response status
+type StatusError struct {
+	// StatusCode is the status of the response.
+	StatusCode int
+	// RetryAfter is how long the server asked to wait before retrying, zero when it did not.
+	RetryAfter time.Duration
+
+	err error
+}
+
+// newStatusError creates a StatusError for resp, describing it with err
+func newStatusError(resp *http.Response, err error) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		err:        err,
+	}
+}
+
+func (e *StatusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.err
+}
+
+// retry calls fn until it succeeds, the error is permanent or the attempts are exhausted,
+// waiting with exponential backoff and jitter in between. The last error is returned.
+func (c *Client) retry(ctx context.Context, operation string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= c.retryAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		delay, retryable := c.backoff(err, attempt)
+		if !retryable {
+			return err
+		}
+
+		c.logger.Info(operation+" failed, retrying", "attempt", attempt, "max", c.retryAttempts, "delay", delay.Round(time.Millisecond), "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns how long to wait before retrying after the attempt failed with err, reporting
+// false when the error is permanent
+func (c *Client) backoff(err error, attempt int) (time.Duration, bool) {
+	// Retrying will not change the content, the client's configuration or an invalid response
+	if errors.Is(err, fs.ErrChecksumMismatch) || errors.Is(err, fs.ErrInvalidPath) || errors.Is(err, ErrOffline) ||
+		errors.Is(err, ErrInvalidRelease) || errors.Is(err, ErrInvalidChecksums) || errors.Is(err, ErrChecksumNotFound) {
+		return 0, false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if !retryableStatus(statusErr.StatusCode) {
+			return 0, false
+		}
+		if statusErr.RetryAfter > 0 {
+			return statusErr.RetryAfter, statusErr.RetryAfter <= maxRetryDelay
+		}
+	}
+
+	delay := min(c.retryDelay<<(attempt-1), maxRetryDelay)
+	if delay <= 0 {
+		return 0, true
+	}
+	// Equal jitter keeps concurrent processes, e.g. CI jobs, from retrying in lockstep
+	//nolint:gosec // G404: jitter does not need a cryptographically secure random number
+	return delay/2 + rand.N(delay/2+1), true
+}
+
+// retryableStatus reports whether a request that failed with the status may succeed when retried
+func retryableStatus(code int) bool {
+	return code >= http.StatusInternalServerError || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+// parseRetryAfter parses the Retry-After header, either a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
@@ -0,0 +1,146 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/go-tw/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checksum is a valid digest, so downloads do not look up the checksum manifest
+const checksum = "abababababababababababababababababababababababababababababababab"
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		status       int
+		headers      map[string]string
+		wantAttempts int32
+	}{
+		{name: "Server error is retried", status: http.StatusBadGateway, wantAttempts: 3},
+		{name: "Request timeout is retried", status: http.StatusRequestTimeout, wantAttempts: 3},
+		{name: "Too many requests is retried", status: http.StatusTooManyRequests, wantAttempts: 3},
+		{name: "Not found is not retried", status: http.StatusNotFound, wantAttempts: 1},
+		{name: "Forbidden is not retried", status: http.StatusForbidden, wantAttempts: 1},
+		{
+			name:         "Retry-After beyond the maximum delay is not retried",
+			status:       http.StatusServiceUnavailable,
+			headers:      map[string]string{"Retry-After": "3600"},
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			tmpDir := t.TempDir()
+			c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs(server.URL, "")
+
+			err := c.Download(context.Background(), "linux", "amd64", "v4.0.0", filepath.Join(tmpDir, "tailwindcss-test"), tmpDir, checksum)
+
+			require.ErrorIs(t, err, client.ErrHTTP)
+			var statusErr *client.StatusError
+			require.ErrorAs(t, err, &statusErr)
+			assert.Equal(t, tt.status, statusErr.StatusCode)
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+
+	t.Run("Configurable attempts", func(t *testing.T) {
+		t.Parallel()
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithRetryAttempts(5).WithTestURLs("", server.URL)
+
+		_, err := c.GetLatestVersion(context.Background())
+
+		require.ErrorIs(t, err, client.ErrHTTP)
+		assert.Equal(t, int32(5), attempts.Load())
+	})
+
+	t.Run("Latest version succeeds after retry", func(t *testing.T) {
+		t.Parallel()
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"tag_name": "v4.1.0"}`))
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL)
+
+		version, err := c.GetLatestVersion(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.1.0", version)
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("Honors Retry-After", func(t *testing.T) {
+		t.Parallel()
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte(`{"tag_name": "v4.1.0"}`))
+		}))
+		defer server.Close()
+
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Millisecond).WithTestURLs("", server.URL)
+
+		start := time.Now()
+		version, err := c.GetLatestVersion(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.1.0", version)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Stops waiting when context is done", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		c := client.New(testLogger(), 30*time.Second).WithRetryDelay(time.Hour).WithTestURLs("", server.URL)
+
+		start := time.Now()
+		_, err := c.GetLatestVersion(ctx)
+
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
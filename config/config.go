@@ -0,0 +1,379 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Piszmog/go-tw/client"
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/tailwind"
+)
+
+// FileName is the name of the configuration file.
+const FileName = "go-tw.json"
+
+// Config controls the behavior of go-tw.
+//
+// Values are resolved with the precedence flags > env > file > defaults. Flags are
+// applied by the caller after Load.
+type Config struct {
+	// Version is the tailwindcss version to use.
+	Version string `json:"version"`
+	// Args are the arguments passed to tailwindcss when none are provided on the command line.
+	Args []string `json:"args"`
+	// Targets are the CSS bundles built by "go-tw build".
+	Targets []Target `json:"targets"`
+	// Concurrency is how many targets are built at the same time, the number of CPUs when zero.
+	Concurrency int `json:"concurrency"`
+	// Hash renames the output of every target to include a hash of its content and records the
+	// names in the manifest.json of the output directory.
+	Hash bool `json:"hash"`
+	// Incremental skips building targets whose inputs are unchanged since their last build.
+	Incremental bool `json:"incremental"`
+	// Compress writes precompressed siblings of the outputs of the targets after every build.
+	Compress *Compress `json:"compress"`
+	// Codegen writes a Go file referencing the outputs of the targets after every build.
+	Codegen *Codegen `json:"codegen"`
+	// CacheDir is the directory tailwindcss is installed to.
+	CacheDir string `json:"cache_dir"`
+	// MirrorURL is the base URL tailwindcss releases are downloaded from.
+	MirrorURL string `json:"mirror_url"`
+	// DownloadTemplate is the layout of release assets on the mirror, using the placeholders
+	// {base}, {version} and {asset}.
+	DownloadTemplate string `json:"download_template"`
+	// APIURL is the base URL of the GitHub API, e.g. of a GitHub Enterprise Server.
+	APIURL string `json:"api_url"`
+	// Timeout is the timeout of HTTP requests.
+	Timeout Duration `json:"timeout"`
+	// RetryAttempts is how many times a failed HTTP request is attempted before giving up.
+	RetryAttempts int `json:"retry_attempts"`
+	// LockTimeout is how long to wait for another go-tw process to finish with the cache directory.
+	LockTimeout Duration `json:"lock_timeout"`
+	// LatestTTL is how long the latest version is cached before asking the GitHub API again.
+	LatestTTL Duration `json:"latest_ttl"`
+	// Offline prevents any network access, tailwindcss must already be installed.
+	Offline bool `json:"offline"`
+	// LogLevel is the level of go-tw's logs.
+	LogLevel string `json:"log_level"`
+	// LogOutput is the format of go-tw's logs.
+	LogOutput string `json:"log_output"`
+
+	// GitHubToken authenticates requests to the GitHub API. It is only read from the
+	// environment so it is never committed.
+	GitHubToken string `json:"-"`
+
+	// Path is the path of the configuration file, empty when no file was found.
+	Path string `json:"-"`
+}
+
+// Dir returns the directory of the configuration file. Relative paths in the file are
+// resolved against it.
+func (c Config) Dir() string {
+	if c.Path == "" {
+		return ""
+	}
+	return filepath.Dir(c.Path)
+}
+
+// Default returns the configuration used when nothing else is specified.
+func Default() Config {
+	return Config{
+		Timeout:       Duration(3 * time.Minute),
+		LatestTTL:     Duration(24 * time.Hour),
+		LockTimeout:   Duration(10 * time.Minute),
+		RetryAttempts: client.DefaultRetryAttempts,
+		LogLevel:      "info",
+		LogOutput:     "text",
+	}
+}
+
+// Load resolves the configuration for the given working directory by applying the
+// configuration file, when one is found, and then the environment over the defaults.
+func Load(dir string) (Config, error) {
+	cfg := Default()
+
+	path, err := Find(dir)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Config{}, err
+	}
+	if path != "" {
+		if err = cfg.readFile(path); err != nil {
+			return Config{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+
+	if err = cfg.applyEnv(); err != nil {
+		return Config{}, err
+	}
+
+	if err = cfg.validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// Find walks up from dir to the module root looking for the configuration file.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := fs.FindModuleRoot(dir)
+	if err != nil && !errors.Is(err, fs.ErrModuleRootNotFound) {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if err = fs.Exists(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, fs.ErrFileNotExists) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if dir == root || root == "" || parent == dir {
+			return "", ErrNotFound
+		}
+		dir = parent
+	}
+}
+
+func (c *Config) readFile(path string) error {
+	//nolint:gosec // G304: path is the discovered configuration file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	c.Path = path
+	if c.CacheDir != "" && !filepath.IsAbs(c.CacheDir) {
+		c.CacheDir = filepath.Join(c.Dir(), c.CacheDir)
+	}
+	return nil
+}
+
+func (c *Config) applyEnv() error {
+	if v, ok := os.LookupEnv("GO_TW_VERSION"); ok && v != "" {
+		c.Version = v
+	}
+	if v, ok := os.LookupEnv("GO_TW_CACHE_DIR"); ok && v != "" {
+		c.CacheDir = v
+	}
+	if v, ok := os.LookupEnv("GO_TW_MIRROR_URL"); ok && v != "" {
+		c.MirrorURL = v
+	}
+	if v, ok := os.LookupEnv("GO_TW_DOWNLOAD_TEMPLATE"); ok && v != "" {
+		c.DownloadTemplate = v
+	}
+	if v, ok := os.LookupEnv("GO_TW_API_URL"); ok && v != "" {
+		c.APIURL = v
+	}
+	// Follows the precedence of the GitHub CLI
+	for _, key := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		if v, ok := os.LookupEnv(key); ok && v != "" {
+			c.GitHubToken = v
+			break
+		}
+	}
+	if v, ok := os.LookupEnv("GO_TW_TIMEOUT"); ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%w: GO_TW_TIMEOUT: %w", ErrInvalid, err)
+		}
+		c.Timeout = Duration(d)
+	}
+	if v, ok := os.LookupEnv("GO_TW_RETRY_ATTEMPTS"); ok && v != "" {
+		attempts, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%w: GO_TW_RETRY_ATTEMPTS: %w", ErrInvalid, err)
+		}
+		c.RetryAttempts = attempts
+	}
+	if v, ok := os.LookupEnv("GO_TW_LOCK_TIMEOUT"); ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%w: GO_TW_LOCK_TIMEOUT: %w", ErrInvalid, err)
+		}
+		c.LockTimeout = Duration(d)
+	}
+	if v, ok := os.LookupEnv("GO_TW_LATEST_TTL"); ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%w: GO_TW_LATEST_TTL: %w", ErrInvalid, err)
+		}
+		c.LatestTTL = Duration(d)
+	}
+	if v, ok := os.LookupEnv("GO_TW_OFFLINE"); ok && v != "" {
+		offline, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%w: GO_TW_OFFLINE: %w", ErrInvalid, err)
+		}
+		c.Offline = offline
+	}
+	if v, ok := os.LookupEnv("LOG_LEVEL"); ok && v != "" {
+		c.LogLevel = v
+	}
+	if v, ok := os.LookupEnv("LOG_OUTPUT"); ok && v != "" {
+		c.LogOutput = v
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
+	if c.DownloadTemplate != "" &&
+		(!strings.Contains(c.DownloadTemplate, "{version}") || !strings.Contains(c.DownloadTemplate, "{asset}")) {
+		return fmt.Errorf("%w: download_template must contain {version} and {asset}: %s", ErrInvalid, c.DownloadTemplate)
+	}
+	if c.RetryAttempts < 1 {
+		return fmt.Errorf("%w: retry_attempts must be at least 1: %d", ErrInvalid, c.RetryAttempts)
+	}
+	if c.Concurrency < 0 {
+		return fmt.Errorf("%w: concurrency must not be negative: %d", ErrInvalid, c.Concurrency)
+	}
+	names := make(map[string]bool, len(c.Targets))
+	for i, target := range c.Targets {
+		if target.Name == "" {
+			return fmt.Errorf("%w: targets[%d] must have a name", ErrInvalid, i)
+		}
+		if names[target.Name] {
+			return fmt.Errorf("%w: target %s is defined more than once", ErrInvalid, target.Name)
+		}
+		names[target.Name] = true
+		if target.Input == "" || target.Output == "" {
+			return fmt.Errorf("%w: target %s must have an input and an output", ErrInvalid, target.Name)
+		}
+	}
+	if c.Compress != nil && (c.Compress.GzipLevel < 0 || c.Compress.GzipLevel > 9) {
+		return fmt.Errorf("%w: compress gzip_level must be from 1 to 9: %d", ErrInvalid, c.Compress.GzipLevel)
+	}
+	if c.Compress != nil && (c.Compress.BrotliLevel < 0 || c.Compress.BrotliLevel > 11) {
+		return fmt.Errorf("%w: compress brotli_level must be from 1 to 11: %d", ErrInvalid, c.Compress.BrotliLevel)
+	}
+	if c.Codegen != nil && c.Codegen.File == "" {
+		return fmt.Errorf("%w: codegen must have a file", ErrInvalid)
+	}
+	if c.Codegen != nil && c.Codegen.Package != "" && !token.IsIdentifier(c.Codegen.Package) {
+		return fmt.Errorf("%w: codegen package is not a valid package name: %s", ErrInvalid, c.Codegen.Package)
+	}
+	return nil
+}
+
+// Target is a CSS bundle built by "go-tw build". Its paths are relative to the configuration file
+// unless they are absolute.
+type Target struct {
+	tailwind.Target
+	// Budget fails the build when the output grows past it.
+	Budget *Budget `json:"budget"`
+}
+
+// Budget is the maximum size of an output. A zero size has no budget.
+type Budget struct {
+	// Raw is the maximum size of the output.
+	Raw Size `json:"raw"`
+	// Gzip is the maximum size of the output compressed with gzip.
+	Gzip Size `json:"gzip"`
+	// Brotli is the maximum size of the output compressed with brotli.
+	Brotli Size `json:"brotli"`
+}
+
+// Size is a number of bytes that is encoded in JSON as either a number or a string with a unit,
+// e.g. "150KB". Units are powers of 1024, so KB and KiB are the same.
+type Size int64
+
+// sizeUnits are the multipliers of the units a size can have, longest first so they match first
+var sizeUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"KB", 1 << 10}, {"MB", 1 << 20}, {"K", 1 << 10}, {"M", 1 << 20}, {"B", 1},
+}
+
+// UnmarshalJSON parses the size from a number of bytes or a string such as "150KB".
+func (s *Size) UnmarshalJSON(data []byte) error {
+	var n int64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*s = Size(n)
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	value := strings.ToUpper(strings.TrimSpace(str))
+	multiplier := 1.0
+	for _, unit := range sizeUnits {
+		if trimmed, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(trimmed), unit.multiplier
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		return fmt.Errorf("invalid size: %q", str)
+	}
+	*s = Size(f * multiplier)
+	return nil
+}
+
+// Compress controls the precompressed siblings of the outputs, e.g. output.css.gz and
+// output.css.br. Levels default to the best compression when zero.
+type Compress struct {
+	// Gzip writes a gzip sibling of every output.
+	Gzip bool `json:"gzip"`
+	// GzipLevel is the gzip compression level from 1 to 9.
+	GzipLevel int `json:"gzip_level"`
+	// Brotli writes a brotli sibling of every output.
+	Brotli bool `json:"brotli"`
+	// BrotliLevel is the brotli compression level from 1 to 11.
+	BrotliLevel int `json:"brotli_level"`
+}
+
+// Codegen controls the Go file referencing the outputs of the targets. It declares an embed.FS
+// of the output directories and the path and subresource integrity of every output.
+type Codegen struct {
+	// File is the path of the Go file, relative to the configuration file. Its directory must
+	// contain the outputs of the targets.
+	File string `json:"file"`
+	// Package is the package name of the Go file, the name of its directory when empty.
+	Package string `json:"package"`
+}
+
+// Duration is a time.Duration that is encoded in JSON as a string, e.g. "3m".
+type Duration time.Duration
+
+// UnmarshalJSON parses the duration from a string such as "30s".
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+var ErrNotFound = errors.New("configuration file not found")
+var ErrInvalid = errors.New("invalid configuration")
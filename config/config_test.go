@@ -0,0 +1,255 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/tailwind"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearEnv unsets the environment variables that override the configuration file
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"GO_TW_VERSION", "GO_TW_CACHE_DIR", "GO_TW_MIRROR_URL", "GO_TW_DOWNLOAD_TEMPLATE", "GO_TW_API_URL", "GH_TOKEN", "GITHUB_TOKEN", "GO_TW_TIMEOUT", "GO_TW_LOCK_TIMEOUT", "GO_TW_RETRY_ATTEMPTS", "GO_TW_LATEST_TTL", "GO_TW_OFFLINE", "LOG_LEVEL", "LOG_OUTPUT"} {
+		t.Setenv(key, "")
+	}
+}
+
+// newModule creates a module in a temp directory with the given configuration file content
+func newModule(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/test\n"), 0600))
+	if content != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.FileName), []byte(content), 0600))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Defaults without file", func(t *testing.T) {
+		clearEnv(t)
+		dir := newModule(t, "")
+
+		cfg, err := config.Load(dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, config.Default(), cfg)
+	})
+
+	t.Run("File overrides defaults", func(t *testing.T) {
+		clearEnv(t)
+		dir := newModule(t, `{
+			"version": "v4.0.7",
+			"args": ["-i", "input.css"],
+			"cache_dir": ".cache",
+			"mirror_url": "https://mirror.example.com",
+			"timeout": "30s",
+			"latest_ttl": "1h",
+			"lock_timeout": "2m",
+			"log_level": "debug"
+		}`)
+
+		cfg, err := config.Load(dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.0.7", cfg.Version)
+		assert.Equal(t, []string{"-i", "input.css"}, cfg.Args)
+		assert.Equal(t, filepath.Join(dir, ".cache"), cfg.CacheDir)
+		assert.Equal(t, "https://mirror.example.com", cfg.MirrorURL)
+		assert.Equal(t, config.Duration(30*time.Second), cfg.Timeout)
+		assert.Equal(t, config.Duration(time.Hour), cfg.LatestTTL)
+		assert.Equal(t, config.Duration(2*time.Minute), cfg.LockTimeout)
+		assert.Equal(t, "debug", cfg.LogLevel)
+		assert.Equal(t, "text", cfg.LogOutput)
+		assert.Equal(t, dir, cfg.Dir())
+	})
+
+	t.Run("Env overrides file", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("GO_TW_VERSION", "v4.1.0")
+		t.Setenv("GO_TW_TIMEOUT", "1m")
+		t.Setenv("LOG_OUTPUT", "json")
+		dir := newModule(t, `{"version": "v4.0.7", "timeout": "30s", "log_output": "text"}`)
+
+		cfg, err := config.Load(dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.1.0", cfg.Version)
+		assert.Equal(t, config.Duration(time.Minute), cfg.Timeout)
+		assert.Equal(t, "json", cfg.LogOutput)
+	})
+
+	t.Run("Offline from env", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("GO_TW_OFFLINE", "1")
+		dir := newModule(t, `{"offline": false}`)
+
+		cfg, err := config.Load(dir)
+
+		require.NoError(t, err)
+		assert.True(t, cfg.Offline)
+	})
+
+	t.Run("Discovered from nested directory", func(t *testing.T) {
+		clearEnv(t)
+		dir := newModule(t, `{"version": "v4.0.7"}`)
+		nested := filepath.Join(dir, "web", "styles")
+		require.NoError(t, os.MkdirAll(nested, 0750))
+
+		cfg, err := config.Load(nested)
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.0.7", cfg.Version)
+		assert.Equal(t, filepath.Join(dir, config.FileName), cfg.Path)
+	})
+
+	t.Run("Invalid file", func(t *testing.T) {
+		clearEnv(t)
+		dir := newModule(t, `{"timeout": "soon"}`)
+
+		_, err := config.Load(dir)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Mirror from env", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("GO_TW_MIRROR_URL", "https://nexus.example.com/repository/github")
+		t.Setenv("GO_TW_DOWNLOAD_TEMPLATE", "{base}/tailwindcss/{version}/{asset}")
+		t.Setenv("GO_TW_API_URL", "https://github.example.com/api/v3")
+		dir := newModule(t, `{"mirror_url": "https://artifactory.example.com"}`)
+
+		cfg, err := config.Load(dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://nexus.example.com/repository/github", cfg.MirrorURL)
+		assert.Equal(t, "{base}/tailwindcss/{version}/{asset}", cfg.DownloadTemplate)
+		assert.Equal(t, "https://github.example.com/api/v3", cfg.APIURL)
+	})
+
+	t.Run("GitHub token from env", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("GITHUB_TOKEN", "github")
+		dir := newModule(t, "")
+
+		cfg, err := config.Load(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "github", cfg.GitHubToken)
+
+		t.Setenv("GH_TOKEN", "gh")
+
+		cfg, err = config.Load(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "gh", cfg.GitHubToken)
+	})
+
+	t.Run("Download template without asset", func(t *testing.T) {
+		clearEnv(t)
+		dir := newModule(t, `{"download_template": "{base}/{version}/tailwindcss"}`)
+
+		_, err := config.Load(dir)
+
+		assert.ErrorIs(t, err, config.ErrInvalid)
+	})
+
+	t.Run("Retry attempts", func(t *testing.T) {
+		clearEnv(t)
+		dir := newModule(t, `{"retry_attempts": 5}`)
+
+		cfg, err := config.Load(dir)
+		require.NoError(t, err)
+		assert.Equal(t, 5, cfg.RetryAttempts)
+
+		t.Setenv("GO_TW_RETRY_ATTEMPTS", "0")
+
+		_, err = config.Load(dir)
+		assert.ErrorIs(t, err, config.ErrInvalid)
+	})
+
+	t.Run("Targets", func(t *testing.T) {
+		clearEnv(t)
+		dir := newModule(t, `{
+			"concurrency": 2,
+			"hash": true,
+			"incremental": true,
+			"compress": {"gzip": true, "brotli": true, "brotli_level": 5},
+			"codegen": {"file": "web/assets.go", "package": "web"},
+			"targets": [
+				{"name": "app", "input": "app.css", "output": "dist/app.css", "args": ["--minify"]},
+				{"name": "admin", "input": "admin.css", "output": "dist/admin.css", "budget": {"raw": "150KB", "gzip": 30000, "brotli": "1.5 MiB"}}
+			]
+		}`)
+
+		cfg, err := config.Load(dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, cfg.Concurrency)
+		assert.True(t, cfg.Hash)
+		assert.True(t, cfg.Incremental)
+		assert.Equal(t, &config.Compress{Gzip: true, Brotli: true, BrotliLevel: 5}, cfg.Compress)
+		assert.Equal(t, &config.Codegen{File: "web/assets.go", Package: "web"}, cfg.Codegen)
+		assert.Equal(t, []config.Target{
+			{Target: tailwind.Target{Name: "app", Input: "app.css", Output: "dist/app.css", Args: []string{"--minify"}}},
+			{Target: tailwind.Target{Name: "admin", Input: "admin.css", Output: "dist/admin.css"}, Budget: &config.Budget{Raw: 150 * 1024, Gzip: 30000, Brotli: 1.5 * 1024 * 1024}},
+		}, cfg.Targets)
+	})
+
+	t.Run("Invalid targets", func(t *testing.T) {
+		clearEnv(t)
+		tests := []struct {
+			name   string
+			config string
+		}{
+			{name: "Missing name", config: `{"targets": [{"input": "app.css", "output": "app.out.css"}]}`},
+			{name: "Duplicate name", config: `{"targets": [{"name": "app", "input": "a.css", "output": "a.out.css"}, {"name": "app", "input": "b.css", "output": "b.out.css"}]}`},
+			{name: "Missing output", config: `{"targets": [{"name": "app", "input": "app.css"}]}`},
+			{name: "Negative concurrency", config: `{"concurrency": -1}`},
+			{name: "Invalid budget", config: `{"targets": [{"name": "app", "input": "app.css", "output": "app.out.css", "budget": {"raw": "big"}}]}`},
+			{name: "Gzip level out of range", config: `{"compress": {"gzip": true, "gzip_level": 10}}`},
+			{name: "Codegen without file", config: `{"codegen": {"package": "web"}}`},
+			{name: "Codegen invalid package", config: `{"codegen": {"file": "assets.go", "package": "go-tw"}}`},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				dir := newModule(t, test.config)
+
+				_, err := config.Load(dir)
+
+				assert.ErrorIs(t, err, config.ErrInvalid)
+			})
+		}
+	})
+
+	t.Run("Invalid env", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("GO_TW_TIMEOUT", "soon")
+		dir := newModule(t, "")
+
+		_, err := config.Load(dir)
+
+		assert.ErrorIs(t, err, config.ErrInvalid)
+	})
+}
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Stops at module root", func(t *testing.T) {
+		t.Parallel()
+		parent := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(parent, config.FileName), []byte("{}"), 0600))
+		dir := filepath.Join(parent, "module")
+		require.NoError(t, os.Mkdir(dir, 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/test\n"), 0600))
+
+		_, err := config.Find(dir)
+
+		assert.ErrorIs(t, err, config.ErrNotFound)
+	})
+}
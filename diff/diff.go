@@ -0,0 +1,247 @@
+// Package diff compares text line by line and formats the differences as a unified diff.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// op is the kind of an edit
+type op int
+
+const (
+	opEqual op = iota
+	opDelete
+	opInsert
+)
+
+// edit is a line kept, deleted from a or inserted from b
+type edit struct {
+	op   op
+	line string
+}
+
+// Unified returns the unified diff from a to b, labeling them nameA and nameB. It is empty
+// when they are equal.
+func Unified(nameA string, nameB string, a []byte, b []byte) string {
+	if string(a) == string(b) {
+		return ""
+	}
+	edits := myers(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	out.WriteString("--- " + nameA + "\n+++ " + nameB + "\n")
+	for _, h := range hunks(edits) {
+		writeHunk(&out, edits, h)
+	}
+	return out.String()
+}
+
+// Stat returns the number of lines inserted and deleted from a to b
+func Stat(a []byte, b []byte) (int, int) {
+	var inserted, deleted int
+	for _, e := range myers(splitLines(a), splitLines(b)) {
+		switch e.op {
+		case opInsert:
+			inserted++
+		case opDelete:
+			deleted++
+		case opEqual:
+		}
+	}
+	return inserted, deleted
+}
+
+// splitLines splits the text into lines, keeping the line endings so a missing final newline
+// is a difference
+func splitLines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// maxCost bounds the number of edits myers searches for, beyond which every differing line is
+// treated as changed so large rewrites do not take quadratic time and memory
+const maxCost = 2000
+
+// myers returns the shortest edit script from a to b using Myers' algorithm
+func myers(a []string, b []string) []edit {
+	// Common lines at the start and end are kept without searching
+	var prefix, suffix []edit
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, edit{op: opEqual, line: a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]edit{{op: opEqual, line: a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	edits := search(a, b)
+	if edits == nil {
+		edits = replace(a, b)
+	}
+	return append(append(prefix, edits...), suffix...)
+}
+
+// search finds the shortest edit script, nil when it is longer than maxCost
+func search(a []string, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace holds v for k in [-d-1, d+1] before each step d
+	var trace [][]int
+
+	for d := 0; d <= min(n+m, maxCost); d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the trace of search back from the end to recover the edits
+func backtrack(a []string, b []string, trace [][]int) []edit {
+	x, y := len(a), len(b)
+	var edits []edit
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{op: opEqual, line: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{op: opInsert, line: b[prevY]})
+			} else {
+				edits = append(edits, edit{op: opDelete, line: a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// replace returns the edits deleting every line of a and inserting every line of b
+func replace(a []string, b []string) []edit {
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, line := range a {
+		edits = append(edits, edit{op: opDelete, line: line})
+	}
+	for _, line := range b {
+		edits = append(edits, edit{op: opInsert, line: line})
+	}
+	return edits
+}
+
+// hunk is a range of the edits shown together
+type hunk struct {
+	start, end int
+}
+
+// hunks groups the changes with their context, merging groups whose context overlaps
+func hunks(edits []edit) []hunk {
+	var result []hunk
+	for i, e := range edits {
+		if e.op == opEqual {
+			continue
+		}
+		start := max(i-contextLines, 0)
+		end := min(i+contextLines+1, len(edits))
+		if len(result) > 0 && start <= result[len(result)-1].end {
+			result[len(result)-1].end = end
+			continue
+		}
+		result = append(result, hunk{start: start, end: end})
+	}
+	return result
+}
+
+// writeHunk writes the header and lines of the hunk
+func writeHunk(out *strings.Builder, edits []edit, h hunk) {
+	// Line numbers are 1-based and count the lines of each side before the hunk
+	lineA, lineB := 1, 1
+	for _, e := range edits[:h.start] {
+		if e.op != opInsert {
+			lineA++
+		}
+		if e.op != opDelete {
+			lineB++
+		}
+	}
+	var countA, countB int
+	for _, e := range edits[h.start:h.end] {
+		if e.op != opInsert {
+			countA++
+		}
+		if e.op != opDelete {
+			countB++
+		}
+	}
+	_, _ = fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(lineA, countA), hunkRange(lineB, countB))
+
+	for _, e := range edits[h.start:h.end] {
+		prefix := " "
+		switch e.op {
+		case opDelete:
+			prefix = "-"
+		case opInsert:
+			prefix = "+"
+		case opEqual:
+		}
+		out.WriteString(prefix + e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start and count of a side of a hunk, an empty side starts at the line
+// before it
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,116 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Piszmog/go-tw/diff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{
+			name:     "Equal",
+			a:        "a\nb\n",
+			b:        "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "Changed line with context",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: `--- a
++++ b
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name: "Separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			expected: `--- a
++++ b
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -7,4 +7,4 @@
+ 7
+ 8
+ 9
+-10
++ten
+`,
+		},
+		{
+			name: "Insert into empty",
+			a:    "",
+			b:    "a\n",
+			expected: `--- a
++++ b
+@@ -0,0 +1 @@
++a
+`,
+		},
+		{
+			name: "Missing final newline",
+			a:    ".a{}\n",
+			b:    ".a{}",
+			expected: `--- a
++++ b
+@@ -1 +1 @@
+-.a{}
++.a{}
+\ No newline at end of file
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.expected, diff.Unified("a", "b", []byte(test.a), []byte(test.b)))
+		})
+	}
+}
+
+func TestUnifiedLargeRewrite(t *testing.T) {
+	t.Parallel()
+
+	var a, b strings.Builder
+	for i := range 5000 {
+		a.WriteString("a" + strings.Repeat("x", i%7) + "\n")
+		b.WriteString("b" + strings.Repeat("x", i%7) + "\n")
+	}
+
+	inserted, deleted := diff.Stat([]byte(a.String()), []byte(b.String()))
+
+	assert.Equal(t, 5000, inserted)
+	assert.Equal(t, 5000, deleted)
+}
+
+func TestStat(t *testing.T) {
+	t.Parallel()
+
+	inserted, deleted := diff.Stat([]byte("a\nb\nc\n"), []byte("a\nc\nd\ne\n"))
+
+	assert.Equal(t, 2, inserted)
+	assert.Equal(t, 1, deleted)
+}
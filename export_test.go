@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/tailwind"
+)
+
+// Exposes the commands and helpers of the main package to its tests
+var (
+	Build       = build
+	Cache       = cache
+	Check       = check
+	Lock        = lock
+	GeneratedBy = generatedBy
+)
+
+// Compare compares the expected content with the committed file at path, returning the status
+// and diff of the result
+func Compare(path string, expected []byte) (string, string) {
+	checked := compare(checkResult{name: "app"}, "app.css", path, expected)
+	return checked.status, checked.diff
+}
+
+// FormatSize formats a size with its change since the previous build
+var FormatSize = formatSize
+
+// SizeReport is the sizes of the output of a target checked against its budget
+type SizeReport struct {
+	Name     string
+	Sizes    asset.Sizes
+	Previous *asset.Sizes
+	Exceeded []string
+}
+
+// CheckBudgets checks the budgets of the targets built to their configured outputs, returning
+// the reports and the error failing the build
+func CheckBudgets(cfg config.Config) ([]SizeReport, error) {
+	built := make([]builtTarget, len(cfg.Targets))
+	for i, target := range cfg.Targets {
+		built[i] = builtTarget{TargetResult: tailwind.TargetResult{Target: target.Target}, Path: resolve(cfg, target.Output)}
+	}
+	reports := checkBudgets(slog.New(slog.DiscardHandler), cfg, built)
+
+	var exported []SizeReport
+	for _, report := range reports {
+		exported = append(exported, SizeReport{
+			Name:     report.name,
+			Sizes:    report.sizes,
+			Previous: report.previous,
+			Exceeded: report.exceeded,
+		})
+	}
+	return exported, budgetError(reports)
+}
@@ -0,0 +1,110 @@
+// Package fingerprint detects whether the inputs of a build changed since it last ran, so
+// unchanged targets are not rebuilt.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Suffix is the suffix of the file storing the fingerprint of an output
+const Suffix = ".fingerprint"
+
+// skippedDirs are never scanned for sources, besides hidden directories
+var skippedDirs = []string{"node_modules", "vendor"}
+
+// Path returns the path of the file storing the fingerprint of the output, a hidden file next
+// to it so it is not embedded or served with the output
+func Path(output string) string {
+	return filepath.Join(filepath.Dir(output), "."+filepath.Base(output)+Suffix)
+}
+
+// Sources hashes the path and content of every file under root that tailwindcss may scan for
+// class names. Hidden files and directories, dependencies and the files for which exclude
+// returns true, e.g. the outputs of the build, are skipped.
+func Sources(root string, exclude func(path string) bool) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || (d.IsDir() && slices.Contains(skippedDirs, d.Name()))) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || exclude(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		_, _ = io.WriteString(h, filepath.ToSlash(rel)+"\x00")
+		return hashFile(h, path)
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// New combines the parts, e.g. the version of tailwindcss and the hash of the sources, into a
+// fingerprint
+func New(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		_, _ = io.WriteString(h, part+"\x00")
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// File returns the hash of the content of the file at path
+func File(path string) (string, error) {
+	h := sha256.New()
+	if err := hashFile(h, path); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Read returns the fingerprint stored at path, empty when there is none
+func Read(path string) (string, error) {
+	//nolint:gosec // G304: path is the fingerprint of a build output
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Write stores the fingerprint at path
+func Write(path string, fingerprint string) error {
+	//nolint:gosec // G306: the fingerprint is a hash and is not sensitive
+	return os.WriteFile(path, []byte(fingerprint+"\n"), 0644)
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path) //nolint:gosec // G304: path is a source file of the project
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	_, err = io.Copy(w, f)
+	return err
+}
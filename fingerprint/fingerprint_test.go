@@ -0,0 +1,97 @@
+package fingerprint_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Piszmog/go-tw/fingerprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes the content to the path relative to dir, creating its directories
+func writeFile(t *testing.T, dir string, path string, content string) {
+	t.Helper()
+	path = filepath.Join(dir, path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestSources(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Changes with sources only", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		writeFile(t, dir, "web/index.html", `<div class="flex">`)
+		writeFile(t, dir, "styles/input.css", `@import "tailwindcss";`)
+		output := filepath.Join(dir, "dist", "output.css")
+		exclude := func(path string) bool { return path == output }
+
+		initial, err := fingerprint.Sources(dir, exclude)
+		require.NoError(t, err)
+
+		// Outputs, hidden files and dependencies are not sources
+		writeFile(t, dir, "dist/output.css", ".flex{display:flex}")
+		writeFile(t, dir, ".git/HEAD", "ref: refs/heads/main")
+		writeFile(t, dir, "node_modules/pkg/index.js", "module.exports = {}")
+		writeFile(t, dir, "dist/.output.css"+fingerprint.Suffix, initial)
+		unchanged, err := fingerprint.Sources(dir, exclude)
+		require.NoError(t, err)
+		assert.Equal(t, initial, unchanged)
+
+		writeFile(t, dir, "web/index.html", `<div class="grid">`)
+		changed, err := fingerprint.Sources(dir, exclude)
+		require.NoError(t, err)
+		assert.NotEqual(t, initial, changed)
+	})
+
+	t.Run("Renamed file changes", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		writeFile(t, dir, "a.html", "content")
+		initial, err := fingerprint.Sources(dir, func(string) bool { return false })
+		require.NoError(t, err)
+
+		require.NoError(t, os.Rename(filepath.Join(dir, "a.html"), filepath.Join(dir, "b.html")))
+		renamed, err := fingerprint.Sources(dir, func(string) bool { return false })
+		require.NoError(t, err)
+
+		assert.NotEqual(t, initial, renamed)
+	})
+}
+
+func TestReadWrite(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Round trip", func(t *testing.T) {
+		t.Parallel()
+		path := fingerprint.Path(filepath.Join(t.TempDir(), "output.css"))
+		assert.Equal(t, ".output.css"+fingerprint.Suffix, filepath.Base(path))
+		expected := fingerprint.New("v4.0.0", "--minify")
+
+		require.NoError(t, fingerprint.Write(path, expected))
+
+		actual, err := fingerprint.Read(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("Missing is empty", func(t *testing.T) {
+		t.Parallel()
+
+		actual, err := fingerprint.Read(filepath.Join(t.TempDir(), ".output.css"+fingerprint.Suffix))
+
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	// Parts are separated so moving characters between them changes the fingerprint
+	assert.NotEqual(t, fingerprint.New("ab", "c"), fingerprint.New("a", "bc"))
+	assert.Equal(t, fingerprint.New("a", "b"), fingerprint.New("a", "b"))
+}
@@ -2,52 +2,86 @@ package fs
 
 import (
 	"errors"
-	"fmt"
-	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/Piszmog/go-tw/semver"
 )
 
 const (
 	PrefixTailwind = "tailwindcss-"
-)
 
-func Write(logger *slog.Logger, reader io.Reader, path string, downloadDir string, expectedSize int64) error {
-	logger.Debug("Writing file", "path", path, "expectedSize", expectedSize)
+	// Temp files do not start with PrefixTailwind so they are never mistaken for an install
+	tempPrefix = ".go-tw-"
+	// tempSuffix is the suffix of the temp files older versions of go-tw downloaded to
+	tempSuffix = ".tmp"
 
-	// Validate path is within download directory
-	cleanPath := filepath.Clean(path)
-	cleanDir := filepath.Clean(downloadDir)
-	if !strings.HasPrefix(cleanPath, cleanDir+string(filepath.Separator)) {
-		return ErrInvalidPath
-	}
+	// assetSuffix is the suffix of the file recording the release asset an install was downloaded from
+	assetSuffix = ".asset"
+
+	// PartialMaxAge is how long an interrupted download is kept to be resumed before it is swept
+	PartialMaxAge = 7 * 24 * time.Hour
+)
 
-	f, err := os.Create(cleanPath)
+// syncDir syncs the directory so a rename within it survives a power loss. Not every
+// platform supports syncing a directory, e.g. Windows, so failures are only logged.
+func syncDir(logger *slog.Logger, dir string) {
+	d, err := os.Open(dir) //nolint:gosec // G304: dir is the download directory
 	if err != nil {
-		return err
+		logger.Debug("failed to open directory to sync", "dir", dir, "error", err)
+		return
 	}
-	defer func() {
-		if closeErr := f.Close(); closeErr != nil {
-			logger.Error("failed to close file", "error", closeErr)
-		}
-	}()
+	if err = d.Sync(); err != nil {
+		logger.Debug("failed to sync directory", "dir", dir, "error", err)
+	}
+	if err = d.Close(); err != nil {
+		logger.Debug("failed to close directory", "dir", dir, "error", err)
+	}
+}
 
-	written, err := io.Copy(f, reader)
+// SweepTempFiles deletes temp files left behind by downloads that were interrupted, and partial
+// downloads that were not resumed within PartialMaxAge. It must only be called while holding the
+// Lock, so it cannot delete the files of a download that is in progress.
+func SweepTempFiles(logger *slog.Logger, downloadDir string) error {
+	entries, err := os.ReadDir(downloadDir)
 	if err != nil {
 		return err
 	}
 
-	// Validate file size if Content-Length was provided
-	if expectedSize > 0 && written != expectedSize {
-		return fmt.Errorf("%w: expected %d bytes, got %d bytes", ErrIncompleteDownload, expectedSize, written)
+	cutoff := time.Now().Add(-PartialMaxAge)
+	for _, entry := range entries {
+		if entry.IsDir() || !isSweepable(entry, cutoff) {
+			continue
+		}
+		logger.Debug("Deleting leftover temp file", "file", entry.Name(), "dir", downloadDir)
+		if err = os.Remove(filepath.Join(downloadDir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
-
-	logger.Debug("File written successfully", "path", path, "bytes", written)
 	return nil
 }
 
+// isSweepable reports whether the entry is a temp file, or a partial download last written
+// before the cutoff
+func isSweepable(entry os.DirEntry, cutoff time.Time) bool {
+	name := entry.Name()
+	if !strings.HasPrefix(name, tempPrefix) {
+		return false
+	}
+	if strings.HasSuffix(name, tempSuffix) {
+		return true
+	}
+	if !isPartial(name) {
+		return false
+	}
+	info, err := entry.Info()
+	return err == nil && info.ModTime().Before(cutoff)
+}
+
 func Exists(path string) error {
 	_, err := os.Stat(path)
 	if err != nil {
@@ -63,35 +97,97 @@ var ErrFileNotExists = errors.New("file does not exist")
 var ErrNotInstalled = errors.New("tailwindcss is not currently installed")
 var ErrInvalidPath = errors.New("invalid path: attempting to write outside cache directory")
 var ErrIncompleteDownload = errors.New("incomplete download")
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+var ErrModuleRootNotFound = errors.New("no go.mod found in any parent directory")
 
-func GetCurrentVersion(path string) (string, error) {
+func GetInstalledVersions(path string) ([]string, error) {
 	entries, err := os.ReadDir(path)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	var versions []string
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
 		if s, hasPrefix := strings.CutPrefix(entry.Name(), PrefixTailwind); hasPrefix {
-			// Remove .exe extension if present (Windows)
-			version := strings.TrimSuffix(s, ".exe")
-			return version, nil
+			versions = append(versions, strings.TrimSuffix(s, ".exe"))
 		}
 	}
 
-	return "", ErrNotInstalled
+	return versions, nil
 }
 
-func GetDownloadDir() (string, error) {
-	cacheDir, err := os.UserCacheDir()
+// GetCurrentVersion returns the highest version installed in the directory, so several installs
+// left in the cache resolve to the newest rather than whichever is listed first.
+func GetCurrentVersion(path string) (string, error) {
+	installed, err := GetInstalledVersions(path)
 	if err != nil {
 		return "", err
 	}
 
-	p := filepath.Join(cacheDir, "go-tw")
+	var highest semver.Version
+	found := false
+	for _, version := range installed {
+		v, parseErr := semver.Parse(version)
+		if parseErr != nil {
+			continue
+		}
+		if !found || v.Compare(highest) > 0 {
+			highest = v
+			found = true
+		}
+	}
+	if !found {
+		return "", ErrNotInstalled
+	}
+	return highest.Original, nil
+}
+
+// FindModuleRoot walks up from dir until it finds the directory containing go.mod
+func FindModuleRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if err = Exists(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		} else if !errors.Is(err, ErrFileNotExists) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrModuleRootNotFound
+		}
+		dir = parent
+	}
+}
+
+func GetDownloadDir() (string, error) {
+	return ResolveDownloadDir("")
+}
+
+// ResolveDownloadDir creates the download directory, defaulting to go-tw in the user's
+// cache directory when dir is empty
+func ResolveDownloadDir(dir string) (string, error) {
+	p := dir
+	if p == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		p = filepath.Join(cacheDir, "go-tw")
+	}
+
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
 
 	if err = os.MkdirAll(p, 0750); err != nil {
 		return "", err
@@ -100,30 +196,108 @@ func GetDownloadDir() (string, error) {
 	return p, nil
 }
 
-func DeleteOtherVersions(logger *slog.Logger, downloadDir string, version string) error {
+// Install is a tailwindcss binary in the download directory
+type Install struct {
+	Version string
+	Path    string
+	// Asset is the release asset the binary was downloaded from, e.g. tailwindcss-linux-x64. It is
+	// empty for binaries installed before it was recorded.
+	Asset    string
+	Size     int64
+	LastUsed time.Time
+}
+
+// assetPath returns the path of the file recording the release asset of the install at path. It
+// does not start with PrefixTailwind so it is never mistaken for an install.
+func assetPath(path string) string {
+	dir, name := filepath.Split(path)
+	return filepath.Join(dir, tempPrefix+name+assetSuffix)
+}
+
+// WriteAsset records the release asset the install at path was downloaded from, so installs for
+// other platforms can be told apart in a shared cache directory.
+func WriteAsset(path string, asset string) error {
+	//nolint:gosec // G306: the asset name is not sensitive
+	return os.WriteFile(assetPath(path), []byte(asset+"\n"), 0644)
+}
+
+// readAsset returns the release asset recorded for the install at path, empty when none is
+func readAsset(path string) string {
+	data, err := os.ReadFile(assetPath(path)) //nolint:gosec // G304: path is in the download directory
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// ListInstalls returns the tailwindcss binaries in the download directory, most recently used first
+func ListInstalls(downloadDir string) ([]Install, error) {
 	entries, err := os.ReadDir(downloadDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var installs []Install
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
-		if strings.HasPrefix(entry.Name(), PrefixTailwind) {
-			// Remove .exe extension if present (Windows) for version comparison
-			fileName := strings.TrimSuffix(entry.Name(), ".exe")
-			if !strings.HasSuffix(fileName, "-"+version) {
-				logger.Debug("Deleting old version", "file", entry.Name(), "dir", downloadDir)
-				if err = os.Remove(filepath.Join(downloadDir, entry.Name())); err != nil {
-					return err
-				}
-			}
+		s, hasPrefix := strings.CutPrefix(entry.Name(), PrefixTailwind)
+		if !hasPrefix {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
 		}
+		path := filepath.Join(downloadDir, entry.Name())
+		installs = append(installs, Install{
+			Version:  strings.TrimSuffix(s, ".exe"),
+			Path:     path,
+			Asset:    readAsset(path),
+			Size:     info.Size(),
+			LastUsed: info.ModTime(),
+		})
 	}
 
-	return nil
+	slices.SortFunc(installs, func(a, b Install) int {
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+	return installs, nil
+}
+
+// Prune deletes all but the keep most recently used tailwindcss binaries, returning the deleted installs
+func Prune(logger *slog.Logger, downloadDir string, keep int) ([]Install, error) {
+	installs, err := ListInstalls(downloadDir)
+	if err != nil {
+		return nil, err
+	}
+	if keep < 0 {
+		keep = 0
+	}
+	if keep >= len(installs) {
+		return nil, nil
+	}
+
+	removed := installs[keep:]
+	for _, install := range removed {
+		logger.Debug("Deleting version", "path", install.Path)
+		if err = os.Remove(install.Path); err != nil {
+			return nil, err
+		}
+		if err = os.Remove(assetPath(install.Path)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
+
+// Touch marks the file as used now, tracking when a tailwindcss binary was last used
+func Touch(path string) error {
+	now := time.Now()
+	return os.Chtimes(path, now, now)
 }
 
 func MakeExecutable(path string) error {
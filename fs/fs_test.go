@@ -1,11 +1,11 @@
 package fs_test
 
 import (
-	"bytes"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/Piszmog/go-tw/fs"
 	"github.com/stretchr/testify/assert"
@@ -17,81 +17,41 @@ func testLogger() *slog.Logger {
 	return slog.New(slog.DiscardHandler)
 }
 
-func TestWrite(t *testing.T) {
+func TestSweepTempFiles(t *testing.T) {
 	t.Parallel()
 	logger := testLogger()
 
-	t.Run("Successful write", func(t *testing.T) {
-		t.Parallel()
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "test.bin")
-		content := []byte("test content")
-
-		err := fs.Write(logger, bytes.NewReader(content), filePath, tmpDir, int64(len(content)))
-
-		require.NoError(t, err)
-
-		// Verify file contents
-		//nolint:gosec // G304: Reading from test temp file, safe
-		written, err := os.ReadFile(filePath)
-		require.NoError(t, err)
-		assert.Equal(t, content, written)
-	})
-
-	t.Run("Invalid path - outside download directory", func(t *testing.T) {
-		t.Parallel()
-		tmpDir := t.TempDir()
-		invalidPath := "/tmp/malicious.bin"
-
-		err := fs.Write(logger, bytes.NewReader([]byte("data")), invalidPath, tmpDir, 4)
-
-		assert.ErrorIs(t, err, fs.ErrInvalidPath)
-	})
-
-	t.Run("Path traversal attempt", func(t *testing.T) {
-		t.Parallel()
-		tmpDir := t.TempDir()
-		maliciousPath := filepath.Join(tmpDir, "../../../etc/passwd")
-
-		err := fs.Write(logger, bytes.NewReader([]byte("data")), maliciousPath, tmpDir, 4)
-
-		assert.ErrorIs(t, err, fs.ErrInvalidPath)
-	})
-
-	t.Run("Size mismatch - too small", func(t *testing.T) {
-		t.Parallel()
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "test.bin")
-		content := []byte("short")
-
-		err := fs.Write(logger, bytes.NewReader(content), filePath, tmpDir, 1000)
-
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "incomplete download")
-	})
-
-	t.Run("Size mismatch - too large", func(t *testing.T) {
-		t.Parallel()
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "test.bin")
-		content := []byte("this is a very long string")
-
-		err := fs.Write(logger, bytes.NewReader(content), filePath, tmpDir, 5)
-
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "incomplete download")
-	})
-
-	t.Run("No size validation when expectedSize is 0", func(t *testing.T) {
-		t.Parallel()
-		tmpDir := t.TempDir()
-		filePath := filepath.Join(tmpDir, "test.bin")
-		content := []byte("any size")
-
-		err := fs.Write(logger, bytes.NewReader(content), filePath, tmpDir, 0)
-
-		require.NoError(t, err)
-	})
+	tmpDir := t.TempDir()
+	for _, name := range []string{
+		".go-tw-123.tmp",
+		".go-tw-tailwindcss-v4.0.0.part",
+		".go-tw-tailwindcss-v4.0.0.part.json",
+		".go-tw-tailwindcss-v4.1.0.part",
+		".go-tw-tailwindcss-v4.1.0.part.json",
+		"tailwindcss-v4.0.0",
+		fs.LockFileName,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte{}, 0600))
+	}
+	abandoned := time.Now().Add(-fs.PartialMaxAge - time.Hour)
+	for _, name := range []string{".go-tw-tailwindcss-v4.0.0.part", ".go-tw-tailwindcss-v4.0.0.part.json"} {
+		require.NoError(t, os.Chtimes(filepath.Join(tmpDir, name), abandoned, abandoned))
+	}
+
+	require.NoError(t, fs.SweepTempFiles(logger, tmpDir))
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{
+		".go-tw-tailwindcss-v4.1.0.part",
+		".go-tw-tailwindcss-v4.1.0.part.json",
+		"tailwindcss-v4.0.0",
+		fs.LockFileName,
+	}, names)
 }
 
 func TestExists(t *testing.T) {
@@ -133,6 +93,18 @@ func TestGetCurrentVersion(t *testing.T) {
 		assert.Equal(t, "v4.0.0", version)
 	})
 
+	t.Run("Highest of several versions", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		for _, name := range []string{"tailwindcss-v3.4.17", "tailwindcss-v4.1.0", "tailwindcss-v4.0.9", "tailwindcss-v4.1.0-beta.1"} {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte{}, 0600))
+		}
+
+		version, err := fs.GetCurrentVersion(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, "v4.1.0", version)
+	})
+
 	t.Run("Windows executable with .exe extension", func(t *testing.T) {
 		t.Parallel()
 		tmpDir := t.TempDir()
@@ -174,6 +146,49 @@ func TestGetCurrentVersion(t *testing.T) {
 	})
 }
 
+func TestGetInstalledVersions(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	for _, name := range []string{"tailwindcss-v4.0.0", "tailwindcss-v4.1.0.exe", "other-file.txt"} {
+		err := os.WriteFile(filepath.Join(tmpDir, name), []byte{}, 0600)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "tailwindcss-v3.0.0"), 0750))
+
+	versions, err := fs.GetInstalledVersions(tmpDir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"v4.0.0", "v4.1.0"}, versions)
+}
+
+func TestFindModuleRoot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Finds go.mod in parent directory", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/test\n"), 0600)
+		require.NoError(t, err)
+		nested := filepath.Join(tmpDir, "a", "b")
+		require.NoError(t, os.MkdirAll(nested, 0750))
+
+		root, err := fs.FindModuleRoot(nested)
+		require.NoError(t, err)
+		assert.Equal(t, tmpDir, root)
+	})
+
+	t.Run("Finds go.mod in same directory", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/test\n"), 0600)
+		require.NoError(t, err)
+
+		root, err := fs.FindModuleRoot(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, tmpDir, root)
+	})
+}
+
 func TestGetDownloadDir(t *testing.T) {
 	t.Parallel()
 
@@ -191,95 +206,117 @@ func TestGetDownloadDir(t *testing.T) {
 	})
 }
 
-func TestDeleteOtherVersions(t *testing.T) {
+func TestResolveDownloadDir(t *testing.T) {
 	t.Parallel()
 
-	logger := testLogger()
-
-	t.Run("Deletes old versions", func(t *testing.T) {
+	t.Run("Creates configured directory", func(t *testing.T) {
 		t.Parallel()
-		tmpDir := t.TempDir()
-
-		// Create multiple versions
-		v1 := filepath.Join(tmpDir, "tailwindcss-v3.0.0")
-		v2 := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
-		v3 := filepath.Join(tmpDir, "tailwindcss-v5.0.0")
+		configured := filepath.Join(t.TempDir(), "cache", "go-tw")
 
-		for _, path := range []string{v1, v2, v3} {
-			err := os.WriteFile(path, []byte{}, 0600)
-			require.NoError(t, err)
-		}
+		dir, err := fs.ResolveDownloadDir(configured)
+		require.NoError(t, err)
+		assert.Equal(t, configured, dir)
 
-		err := fs.DeleteOtherVersions(logger, tmpDir, "v4.0.0")
+		info, err := os.Stat(dir)
 		require.NoError(t, err)
+		assert.True(t, info.IsDir())
+	})
+}
 
-		// v4.0.0 should exist
-		assert.NoError(t, fs.Exists(v2))
+func TestListInstalls(t *testing.T) {
+	t.Parallel()
 
-		// Others should be deleted
-		require.ErrorIs(t, fs.Exists(v1), fs.ErrFileNotExists)
-		require.ErrorIs(t, fs.Exists(v3), fs.ErrFileNotExists)
-	})
+	tmpDir := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"tailwindcss-v4.0.0", "tailwindcss-v4.1.0", "other-file.txt"} {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("content"), 0600))
+		require.NoError(t, os.Chtimes(path, now, now.Add(time.Duration(i)*time.Hour)))
+	}
+	require.NoError(t, fs.WriteAsset(filepath.Join(tmpDir, "tailwindcss-v4.1.0"), "tailwindcss-linux-arm64-musl"))
+
+	installs, err := fs.ListInstalls(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, installs, 2)
+	assert.Equal(t, "v4.1.0", installs[0].Version)
+	assert.Equal(t, filepath.Join(tmpDir, "tailwindcss-v4.1.0"), installs[0].Path)
+	assert.Equal(t, "tailwindcss-linux-arm64-musl", installs[0].Asset)
+	assert.Equal(t, int64(7), installs[0].Size)
+	assert.Equal(t, "v4.0.0", installs[1].Version)
+	assert.Empty(t, installs[1].Asset)
+}
 
-	t.Run("Handles Windows .exe files", func(t *testing.T) {
-		t.Parallel()
-		tmpDir := t.TempDir()
+func TestPrune(t *testing.T) {
+	t.Parallel()
 
-		v1 := filepath.Join(tmpDir, "tailwindcss-v3.0.0.exe")
-		v2 := filepath.Join(tmpDir, "tailwindcss-v4.0.0.exe")
+	logger := testLogger()
 
-		for _, path := range []string{v1, v2} {
-			err := os.WriteFile(path, []byte{}, 0600)
-			require.NoError(t, err)
+	setup := func(t *testing.T) string {
+		t.Helper()
+		tmpDir := t.TempDir()
+		now := time.Now()
+		for i, name := range []string{"tailwindcss-v3.0.0", "tailwindcss-v4.0.0", "tailwindcss-v4.1.0"} {
+			path := filepath.Join(tmpDir, name)
+			require.NoError(t, os.WriteFile(path, []byte{}, 0600))
+			require.NoError(t, os.Chtimes(path, now, now.Add(time.Duration(i)*time.Hour)))
 		}
+		return tmpDir
+	}
+
+	t.Run("Keeps most recently used", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setup(t)
 
-		err := fs.DeleteOtherVersions(logger, tmpDir, "v4.0.0")
+		removed, err := fs.Prune(logger, tmpDir, 1)
 		require.NoError(t, err)
+		require.Len(t, removed, 2)
 
-		assert.NoError(t, fs.Exists(v2))
-		assert.ErrorIs(t, fs.Exists(v1), fs.ErrFileNotExists)
+		versions, err := fs.GetInstalledVersions(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v4.1.0"}, versions)
 	})
 
-	t.Run("Keeps non-tailwind files", func(t *testing.T) {
+	t.Run("Keep zero removes everything", func(t *testing.T) {
 		t.Parallel()
-		tmpDir := t.TempDir()
+		tmpDir := setup(t)
 
-		tailwind := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
-		other := filepath.Join(tmpDir, "other-file.txt")
-
-		for _, path := range []string{tailwind, other} {
-			err := os.WriteFile(path, []byte{}, 0600)
-			require.NoError(t, err)
-		}
+		require.NoError(t, fs.WriteAsset(filepath.Join(tmpDir, "tailwindcss-v4.0.0"), "tailwindcss-linux-x64"))
 
-		err := fs.DeleteOtherVersions(logger, tmpDir, "v4.0.0")
+		removed, err := fs.Prune(logger, tmpDir, 0)
 		require.NoError(t, err)
+		assert.Len(t, removed, 3)
 
-		// Both should still exist
-		assert.NoError(t, fs.Exists(tailwind))
-		assert.NoError(t, fs.Exists(other))
+		_, err = fs.GetCurrentVersion(tmpDir)
+		assert.ErrorIs(t, err, fs.ErrNotInstalled)
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "recorded assets are deleted with their install")
 	})
 
-	t.Run("Ignores directories", func(t *testing.T) {
+	t.Run("Keep more than installed", func(t *testing.T) {
 		t.Parallel()
-		tmpDir := t.TempDir()
+		tmpDir := setup(t)
 
-		file := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
-		dir := filepath.Join(tmpDir, "tailwindcss-v3.0.0")
-
-		err := os.WriteFile(file, []byte{}, 0600)
-		require.NoError(t, err)
-		err = os.Mkdir(dir, 0750)
+		removed, err := fs.Prune(logger, tmpDir, 5)
 		require.NoError(t, err)
+		assert.Empty(t, removed)
+	})
+}
 
-		err = fs.DeleteOtherVersions(logger, tmpDir, "v4.0.0")
-		require.NoError(t, err)
+func TestTouch(t *testing.T) {
+	t.Parallel()
 
-		// Directory should still exist (not deleted)
-		info, err := os.Stat(dir)
-		require.NoError(t, err)
-		assert.True(t, info.IsDir())
-	})
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
+	require.NoError(t, os.WriteFile(path, []byte{}, 0600))
+	old := time.Now().Add(-24 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	require.NoError(t, fs.Touch(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), info.ModTime(), time.Minute)
 }
 
 func TestMakeExecutable(t *testing.T) {
@@ -0,0 +1,199 @@
+package fs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// LockFileName is the name of the file guarding the download directory
+	LockFileName = ".go-tw-cache.lock"
+	// lockStaleAfter is how long a lock can go without a heartbeat before it is considered
+	// abandoned by a process that was killed
+	lockStaleAfter = 30 * time.Second
+	// lockHeartbeat is how often the holder of a lock refreshes it
+	lockHeartbeat = lockStaleAfter / 3
+	// lockPollInterval is how often a waiting process retries acquiring the lock
+	lockPollInterval = 100 * time.Millisecond
+)
+
+// Lock is an advisory lock on the download directory, held while installing or deleting
+// tailwindcss binaries so concurrent go-tw processes do not race each other.
+type Lock struct {
+	logger *slog.Logger
+	path   string
+	token  string
+	stop   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// AcquireLock waits up to timeout to acquire the lock on the download directory. A lock that
+// has not been refreshed by its holder within lockStaleAfter is assumed abandoned and taken over.
+func AcquireLock(ctx context.Context, logger *slog.Logger, downloadDir string, timeout time.Duration) (*Lock, error) {
+	path := filepath.Join(downloadDir, LockFileName)
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	waiting := false
+	for {
+		acquired, err := tryLock(path, token)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			logger.Debug("Acquired cache lock", "path", path)
+			l := &Lock{logger: logger, path: path, token: token, stop: make(chan struct{})}
+			l.wg.Add(1)
+			go l.heartbeat()
+			return l, nil
+		}
+
+		if recoverStaleLock(logger, path) {
+			continue
+		}
+
+		if !waiting {
+			logger.Info("Waiting for another go-tw process to release the cache lock", "path", path)
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%w: %s held for longer than %s", ErrLockTimeout, path, timeout)
+			}
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// Release releases the lock. It is safe to call more than once.
+func (l *Lock) Release() error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		l.wg.Wait()
+
+		// The lock may have been taken over if this process stalled past lockStaleAfter
+		if owner, readErr := readLockToken(l.path); readErr != nil || owner != l.token {
+			l.logger.Warn("Cache lock was taken over by another process", "path", l.path)
+			return
+		}
+		if removeErr := os.Remove(l.path); removeErr != nil && !os.IsNotExist(removeErr) {
+			err = removeErr
+			return
+		}
+		l.logger.Debug("Released cache lock", "path", l.path)
+	})
+	return err
+}
+
+// heartbeat refreshes the modification time of the lock so other processes do not treat it as stale
+func (l *Lock) heartbeat() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(lockHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := Touch(l.path); err != nil {
+				l.logger.Debug("Failed to refresh cache lock", "path", l.path, "error", err)
+			}
+		}
+	}
+}
+
+// tryLock atomically creates the lock file, reporting false if it already exists
+func tryLock(path string, token string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) //nolint:gosec // G304: path is in the download directory
+	if err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, writeErr := f.WriteString(token)
+	closeErr := f.Close()
+	if err = errors.Join(writeErr, closeErr); err != nil {
+		_ = os.Remove(path)
+		return false, err
+	}
+	return true, nil
+}
+
+// recoverStaleLock removes the lock if its holder stopped refreshing it, reporting whether it did
+func recoverStaleLock(logger *slog.Logger, path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		// The lock was released in the meantime
+		return os.IsNotExist(err)
+	}
+	if time.Since(info.ModTime()) < lockStaleAfter {
+		return false
+	}
+
+	staleToken, err := readLockToken(path)
+	if err != nil {
+		return false
+	}
+
+	// Move the lock aside before deleting it, so two processes recovering the same stale
+	// lock cannot delete a lock the other has just acquired
+	aside := path + "." + strconv.Itoa(os.Getpid()) + ".stale"
+	if err = os.Rename(path, aside); err != nil {
+		return false
+	}
+	defer func() {
+		_ = os.Remove(aside)
+	}()
+
+	if token, readErr := readLockToken(aside); readErr != nil || token != staleToken {
+		// Another process acquired the lock after it was checked, put it back
+		if linkErr := os.Link(aside, path); linkErr != nil {
+			logger.Debug("Failed to restore cache lock", "path", path, "error", linkErr)
+		}
+		return false
+	}
+
+	logger.Warn("Recovered stale cache lock", "path", path, "age", time.Since(info.ModTime()).Round(time.Second))
+	return true
+}
+
+func readLockToken(path string) (string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is in the download directory
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// newLockToken identifies the holder of a lock, the PID is included to help debugging
+func newLockToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(os.Getpid()) + "-" + hex.EncodeToString(b), nil
+}
+
+var ErrLockTimeout = errors.New("timed out waiting for cache lock")
@@ -0,0 +1,119 @@
+package fs_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireLock(t *testing.T) {
+	t.Parallel()
+
+	logger := testLogger()
+
+	t.Run("Acquire and release", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+
+		lock, err := fs.AcquireLock(context.Background(), logger, tmpDir, time.Second)
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(tmpDir, fs.LockFileName))
+
+		require.NoError(t, lock.Release())
+		assert.NoFileExists(t, filepath.Join(tmpDir, fs.LockFileName))
+
+		// Releasing twice is a no-op
+		require.NoError(t, lock.Release())
+	})
+
+	t.Run("Times out while held", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+
+		lock, err := fs.AcquireLock(context.Background(), logger, tmpDir, time.Second)
+		require.NoError(t, err)
+		defer func() {
+			_ = lock.Release()
+		}()
+
+		_, err = fs.AcquireLock(context.Background(), logger, tmpDir, 200*time.Millisecond)
+		assert.ErrorIs(t, err, fs.ErrLockTimeout)
+	})
+
+	t.Run("Waits for release", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+
+		lock, err := fs.AcquireLock(context.Background(), logger, tmpDir, time.Second)
+		require.NoError(t, err)
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			_ = lock.Release()
+		}()
+
+		second, err := fs.AcquireLock(context.Background(), logger, tmpDir, 5*time.Second)
+		require.NoError(t, err)
+		require.NoError(t, second.Release())
+	})
+
+	t.Run("Context cancellation", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+
+		lock, err := fs.AcquireLock(context.Background(), logger, tmpDir, time.Second)
+		require.NoError(t, err)
+		defer func() {
+			_ = lock.Release()
+		}()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = fs.AcquireLock(ctx, logger, tmpDir, time.Minute)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Recovers stale lock", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, fs.LockFileName)
+		require.NoError(t, os.WriteFile(path, []byte("12345-dead"), 0600))
+		old := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(path, old, old))
+
+		lock, err := fs.AcquireLock(context.Background(), logger, tmpDir, time.Second)
+		require.NoError(t, err)
+		require.NoError(t, lock.Release())
+	})
+
+	t.Run("Mutual exclusion", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+
+		var holders atomic.Int32
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				lock, err := fs.AcquireLock(context.Background(), logger, tmpDir, 10*time.Second)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, int32(1), holders.Add(1))
+				time.Sleep(10 * time.Millisecond)
+				holders.Add(-1)
+				assert.NoError(t, lock.Release())
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,226 @@
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// partialSuffix is the suffix of an interrupted download, kept so it can be resumed
+	partialSuffix = ".part"
+	// partialMetaSuffix is the suffix of the sidecar file describing an interrupted download
+	partialMetaSuffix = partialSuffix + ".json"
+)
+
+// Partial describes a download that was interrupted, so it can be resumed with a Range request
+// instead of starting over.
+type Partial struct {
+	// URL is the URL the content was downloaded from.
+	URL string `json:"url"`
+	// ETag is the strong ETag of the content, used to check it has not changed since.
+	ETag string `json:"etag,omitempty"`
+	// LastModified is used to check the content has not changed when there is no ETag.
+	LastModified string `json:"last_modified,omitempty"`
+	// Received is the number of bytes already written to the partial file.
+	Received int64 `json:"received"`
+}
+
+// Validator returns the value of the If-Range header to resume the download with, empty when
+// the download cannot be resumed safely.
+func (p Partial) Validator() string {
+	// A weak ETag cannot be used with If-Range
+	if p.ETag != "" && !strings.HasPrefix(p.ETag, "W/") {
+		return p.ETag
+	}
+	return p.LastModified
+}
+
+// partialPaths returns the paths of the partial file and its sidecar for the install at path.
+// They do not start with PrefixTailwind so they are never mistaken for an install.
+func partialPaths(path string) (string, string) {
+	dir, name := filepath.Split(path)
+	partialPath := filepath.Join(dir, tempPrefix+name+partialSuffix)
+	return partialPath, partialPath + ".json"
+}
+
+// ReadPartial returns the interrupted download of the install at path. When there is nothing to
+// resume from url, a Partial that starts from the beginning is returned and any leftover files
+// are deleted.
+func ReadPartial(logger *slog.Logger, path string, url string) Partial {
+	partialPath, metaPath := partialPaths(filepath.Clean(path))
+
+	var partial Partial
+	data, err := os.ReadFile(metaPath) //nolint:gosec // G304: path is in the download directory
+	if err == nil {
+		err = json.Unmarshal(data, &partial)
+	}
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Debug("Ignoring unreadable partial download", "path", metaPath, "error", err)
+		}
+		DeletePartial(logger, path)
+		return Partial{URL: url}
+	}
+
+	info, err := os.Stat(partialPath)
+	if err != nil || partial.URL != url || partial.Validator() == "" || partial.Received <= 0 {
+		DeletePartial(logger, path)
+		return Partial{URL: url}
+	}
+
+	// The sidecar is only updated once the partial file is synced, so anything past what it
+	// records may not have reached the disk
+	partial.Received = min(partial.Received, info.Size())
+	logger.Debug("Found partial download", "path", partialPath, "received", partial.Received)
+	return partial
+}
+
+// DeletePartial deletes the interrupted download of the install at path, if there is one.
+func DeletePartial(logger *slog.Logger, path string) {
+	partialPath, metaPath := partialPaths(filepath.Clean(path))
+	for _, p := range []string{partialPath, metaPath} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			logger.Error("failed to remove partial download", "path", p, "error", err)
+		}
+	}
+}
+
+// DeletePartials deletes every interrupted download in the download directory.
+func DeletePartials(logger *slog.Logger, downloadDir string) error {
+	entries, err := os.ReadDir(downloadDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, tempPrefix) || !isPartial(name) {
+			continue
+		}
+		logger.Debug("Deleting partial download", "file", name, "dir", downloadDir)
+		if err = os.Remove(filepath.Join(downloadDir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPartial reports whether the name is that of a partial download or its sidecar
+func isPartial(name string) bool {
+	return strings.HasSuffix(name, partialSuffix) || strings.HasSuffix(name, partialMetaSuffix)
+}
+
+// WritePartial appends the content of reader to the partial download of the install at path,
+// starting at partial.Received. The partial file is synced to disk, and once the download is
+// complete it is verified, made executable and only then renamed into place, so an interrupted
+// download never leaves a file that looks installed. When the reader fails the partial download
+// is kept so it can be resumed. Every chunk written is also written to progress, when it is not
+// nil.
+func WritePartial(logger *slog.Logger, reader io.Reader, path string, downloadDir string, partial Partial, expectedSize int64, expectedChecksum string, progress io.Writer) error {
+	logger.Debug("Writing partial file", "path", path, "offset", partial.Received, "expectedSize", expectedSize, "expectedChecksum", expectedChecksum)
+
+	// Validate path is within download directory
+	cleanPath := filepath.Clean(path)
+	cleanDir := filepath.Clean(downloadDir)
+	if !strings.HasPrefix(cleanPath, cleanDir+string(filepath.Separator)) {
+		return ErrInvalidPath
+	}
+	partialPath, metaPath := partialPaths(cleanPath)
+
+	written, err := appendToPartial(partialPath, reader, partial.Received, progress)
+	partial.Received += written
+	if metaErr := writePartialMeta(metaPath, partial); metaErr != nil {
+		logger.Debug("failed to record partial download", "path", metaPath, "error", metaErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Whatever is wrong with a complete download, resuming it will not fix it
+	if expectedSize > 0 && partial.Received != expectedSize {
+		DeletePartial(logger, cleanPath)
+		return fmt.Errorf("%w: expected %d bytes, got %d bytes", ErrIncompleteDownload, expectedSize, partial.Received)
+	}
+
+	checksum, err := hashFile(partialPath)
+	if err != nil {
+		return err
+	}
+	if expectedChecksum != "" && !strings.EqualFold(checksum, expectedChecksum) {
+		DeletePartial(logger, cleanPath)
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expectedChecksum, checksum)
+	}
+
+	if err = MakeExecutable(partialPath); err != nil {
+		return err
+	}
+	if err = os.Rename(partialPath, cleanPath); err != nil {
+		return err
+	}
+	DeletePartial(logger, cleanPath)
+	syncDir(logger, cleanDir)
+
+	logger.Debug("File written successfully", "path", path, "bytes", partial.Received, "checksum", checksum)
+	return nil
+}
+
+// appendToPartial writes the reader to the partial file at offset, discarding anything after
+// it, and syncs it to disk. The number of bytes synced is returned even when the reader fails.
+func appendToPartial(path string, reader io.Reader, offset int64, progress io.Writer) (int64, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0600) //nolint:gosec // G304: path is in the download directory
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := func() (int64, error) {
+		if truncErr := f.Truncate(offset); truncErr != nil {
+			return 0, truncErr
+		}
+		if _, seekErr := f.Seek(offset, io.SeekStart); seekErr != nil {
+			return 0, seekErr
+		}
+		var w io.Writer = f
+		if progress != nil {
+			w = io.MultiWriter(f, progress)
+		}
+		n, copyErr := io.Copy(w, reader)
+		return n, errors.Join(copyErr, f.Sync())
+	}()
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	return written, err
+}
+
+func writePartialMeta(path string, partial Partial) error {
+	data, err := json.Marshal(partial)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// hashFile returns the hex encoded SHA-256 digest of the file.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is in the download directory
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
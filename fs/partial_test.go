@@ -0,0 +1,196 @@
+package fs_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const partialURL = "https://example.com/tailwindcss-linux-x64"
+
+func TestWritePartial(t *testing.T) {
+	t.Parallel()
+	logger := testLogger()
+	content := []byte("tailwindcss binary content")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	t.Run("Resumes from interrupted write", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
+		partial := fs.Partial{URL: partialURL, ETag: `"v1"`}
+		reader := io.MultiReader(bytes.NewReader(content[:10]), iotest.ErrReader(io.ErrUnexpectedEOF))
+
+		err := fs.WritePartial(logger, reader, filePath, tmpDir, partial, int64(len(content)), checksum, nil)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.NoFileExists(t, filePath)
+
+		partial = fs.ReadPartial(logger, filePath, partialURL)
+		assert.Equal(t, fs.Partial{URL: partialURL, ETag: `"v1"`, Received: 10}, partial)
+
+		err = fs.WritePartial(logger, bytes.NewReader(content[10:]), filePath, tmpDir, partial, int64(len(content)), checksum, nil)
+		require.NoError(t, err)
+
+		//nolint:gosec // G304: Reading from test temp file, safe
+		written, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, content, written)
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "partial download should be cleaned up")
+	})
+
+	t.Run("Checksum mismatch discards partial", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
+		partial := fs.Partial{URL: partialURL, ETag: `"v1"`}
+
+		err := fs.WritePartial(logger, bytes.NewReader([]byte("tampered binary content!!")), filePath, tmpDir, partial, 0, checksum, nil)
+
+		require.ErrorIs(t, err, fs.ErrChecksumMismatch)
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Invalid path", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+
+		err := fs.WritePartial(logger, bytes.NewReader(content), "/tmp/malicious.bin", tmpDir, fs.Partial{URL: partialURL}, 0, "", nil)
+
+		assert.ErrorIs(t, err, fs.ErrInvalidPath)
+	})
+
+	t.Run("Path traversal attempt", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		maliciousPath := filepath.Join(tmpDir, "../../../etc/passwd")
+
+		err := fs.WritePartial(logger, bytes.NewReader(content), maliciousPath, tmpDir, fs.Partial{URL: partialURL}, 0, "", nil)
+
+		assert.ErrorIs(t, err, fs.ErrInvalidPath)
+	})
+
+	t.Run("Size mismatch discards partial", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
+
+		err := fs.WritePartial(logger, bytes.NewReader(content), filePath, tmpDir, fs.Partial{URL: partialURL, ETag: `"v1"`}, 5, "", nil)
+
+		require.ErrorIs(t, err, fs.ErrIncompleteDownload)
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Installed file is executable", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
+
+		err := fs.WritePartial(logger, bytes.NewReader(content), filePath, tmpDir, fs.Partial{URL: partialURL}, int64(len(content)), checksum, nil)
+
+		require.NoError(t, err)
+		info, err := os.Stat(filePath)
+		require.NoError(t, err)
+		assert.NotEqual(t, 0, info.Mode()&0100, "File should be executable by owner")
+	})
+
+	t.Run("Failed verification keeps existing file", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
+		require.NoError(t, os.WriteFile(filePath, []byte("existing"), 0600))
+
+		err := fs.WritePartial(logger, bytes.NewReader([]byte("tampered")), filePath, tmpDir, fs.Partial{URL: partialURL}, 0, checksum, nil)
+
+		require.ErrorIs(t, err, fs.ErrChecksumMismatch)
+		//nolint:gosec // G304: Reading from test temp file, safe
+		existing, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("existing"), existing)
+	})
+}
+
+func TestReadPartial(t *testing.T) {
+	t.Parallel()
+	logger := testLogger()
+
+	// interrupt leaves a partial download of 5 bytes for the install at path
+	interrupt := func(t *testing.T, path string, partial fs.Partial) {
+		t.Helper()
+		reader := io.MultiReader(bytes.NewReader([]byte("12345")), iotest.ErrReader(io.ErrUnexpectedEOF))
+		err := fs.WritePartial(logger, reader, path, filepath.Dir(path), partial, 0, "", nil)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	}
+
+	t.Run("Nothing to resume", func(t *testing.T) {
+		t.Parallel()
+		filePath := filepath.Join(t.TempDir(), "tailwindcss-v4.0.0")
+
+		assert.Equal(t, fs.Partial{URL: partialURL}, fs.ReadPartial(logger, filePath, partialURL))
+	})
+
+	t.Run("Different URL starts over", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "tailwindcss-v4.0.0")
+		interrupt(t, filePath, fs.Partial{URL: partialURL, ETag: `"v1"`})
+
+		partial := fs.ReadPartial(logger, filePath, "https://mirror.example.com/tailwindcss-linux-x64")
+
+		assert.Equal(t, fs.Partial{URL: "https://mirror.example.com/tailwindcss-linux-x64"}, partial)
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Weak ETag cannot be resumed", func(t *testing.T) {
+		t.Parallel()
+		filePath := filepath.Join(t.TempDir(), "tailwindcss-v4.0.0")
+		interrupt(t, filePath, fs.Partial{URL: partialURL, ETag: `W/"v1"`})
+
+		assert.Equal(t, fs.Partial{URL: partialURL}, fs.ReadPartial(logger, filePath, partialURL))
+	})
+
+	t.Run("Last-Modified is used without ETag", func(t *testing.T) {
+		t.Parallel()
+		filePath := filepath.Join(t.TempDir(), "tailwindcss-v4.0.0")
+		interrupt(t, filePath, fs.Partial{URL: partialURL, LastModified: "Wed, 21 Oct 2015 07:28:00 GMT"})
+
+		partial := fs.ReadPartial(logger, filePath, partialURL)
+
+		assert.Equal(t, int64(5), partial.Received)
+		assert.Equal(t, "Wed, 21 Oct 2015 07:28:00 GMT", partial.Validator())
+	})
+}
+
+func TestDeletePartials(t *testing.T) {
+	t.Parallel()
+	logger := testLogger()
+
+	tmpDir := t.TempDir()
+	for _, name := range []string{".go-tw-tailwindcss-v4.0.0.part", ".go-tw-tailwindcss-v4.0.0.part.json", "tailwindcss-v4.1.0"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte{}, 0600))
+	}
+
+	require.NoError(t, fs.DeletePartials(logger, tmpDir))
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "tailwindcss-v4.1.0", entries[0].Name())
+}
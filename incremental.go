@@ -0,0 +1,111 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/fingerprint"
+	"github.com/Piszmog/go-tw/tailwind"
+)
+
+// incremental skips building targets whose inputs are unchanged since their last build
+type incremental struct {
+	cfg     config.Config
+	version string
+	hashed  bool
+	// sources is the hash of the files tailwindcss may scan for class names
+	sources string
+}
+
+// newIncremental fingerprints the sources in the directory of the configuration file, which
+// tailwindcss scans for class names, excluding what the build itself writes
+func newIncremental(cfg config.Config, version string, hashed bool) (*incremental, error) {
+	sources, err := fingerprint.Sources(cfg.Dir(), generatedBy(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return &incremental{cfg: cfg, version: version, hashed: hashed, sources: sources}, nil
+}
+
+// generatedBy returns whether a path is written by the build of the configuration: an output,
+// its hashed names and precompressed siblings, the manifest of an output directory or the
+// generated Go code
+func generatedBy(cfg config.Config) func(path string) bool {
+	outputs := make(map[string]bool, len(cfg.Targets))
+	outputDirs := make(map[string]bool, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		output := resolve(cfg, target.Output)
+		outputs[output] = true
+		outputDirs[filepath.Dir(output)] = true
+	}
+	generated := ""
+	if cfg.Codegen != nil {
+		generated = resolve(cfg, cfg.Codegen.File)
+	}
+
+	return func(path string) bool {
+		dir, name := filepath.Split(path)
+		dir = filepath.Clean(dir)
+		return path == generated ||
+			outputs[filepath.Join(dir, asset.TrimCompressed(asset.Unhash(name)))] ||
+			(outputDirs[dir] && name == asset.ManifestFileName)
+	}
+}
+
+// fingerprint returns the fingerprint of the inputs of the target: the version of tailwindcss,
+// the arguments, the input CSS and the sources
+func (i *incremental) fingerprint(target tailwind.Target) (string, error) {
+	input, err := fingerprint.File(resolve(i.cfg, target.Input))
+	if err != nil {
+		return "", err
+	}
+	return fingerprint.New(
+		i.version,
+		strings.Join(target.Args, "\x00"),
+		strconv.FormatBool(i.hashed),
+		input,
+		i.sources,
+	), nil
+}
+
+// upToDate reports whether the target was last built from the same inputs and its output still
+// exists, pointing the target at the output when it does
+func (i *incremental) upToDate(b *builtTarget) bool {
+	stored, err := fingerprint.Read(fingerprint.Path(b.Path))
+	if err != nil || stored != b.Fingerprint {
+		return false
+	}
+	path, err := outputPath(b.Path, i.hashed)
+	if err != nil {
+		return false
+	}
+	if _, err = os.Stat(path); err != nil {
+		return false
+	}
+	b.Path = path
+	return true
+}
+
+// invalidate removes the fingerprint of a target about to be rebuilt, so a failed build is never
+// mistaken for an up to date one
+func (i *incremental) invalidate(b *builtTarget) {
+	_ = os.Remove(fingerprint.Path(b.Path))
+}
+
+// record stores the fingerprints of the targets that were built successfully
+func (i *incremental) record(logger *slog.Logger, built []builtTarget) {
+	for _, b := range built {
+		if b.Err != nil || b.Skipped {
+			continue
+		}
+		path := fingerprint.Path(resolve(i.cfg, b.Target.Output))
+		if err := fingerprint.Write(path, b.Fingerprint); err != nil {
+			logger.Warn("Failed to write fingerprint, the target is rebuilt next time", "target", b.Target.Name, "error", err)
+		}
+	}
+}
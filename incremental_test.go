@@ -0,0 +1,267 @@
+package main_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	main "github.com/Piszmog/go-tw"
+	"github.com/Piszmog/go-tw/asset"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/fingerprint"
+	"github.com/Piszmog/go-tw/tailwind"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// project is a configured project whose app target is built by a fake tailwindcss
+type project struct {
+	dir  string
+	cfg  config.Config
+	opts tailwind.Options
+	// runs is the file the fake tailwindcss counts its runs in
+	runs string
+}
+
+func newProject(t *testing.T, css string) *project {
+	t.Helper()
+	dir := t.TempDir()
+	cacheDir := t.TempDir()
+	p := &project{
+		dir: dir,
+		cfg: config.Config{
+			Path: filepath.Join(dir, config.FileName),
+			Targets: []config.Target{
+				{Target: tailwind.Target{Name: "app", Input: "styles/input.css", Output: "dist/app.css"}},
+			},
+		},
+		opts: testOptions(cacheDir, dir),
+		runs: filepath.Join(cacheDir, "runs"),
+	}
+	installScript(t, cacheDir, "v4.0.0", fmt.Sprintf(fakeTailwind, p.runs, css))
+	writeFile(t, dir, "styles/input.css", `@import "tailwindcss";`)
+	writeFile(t, dir, "templates/index.html", `<div class="flex"></div>`)
+	return p
+}
+
+func (p *project) build(args ...string) error {
+	return main.Build(context.Background(), p.opts, p.cfg, args)
+}
+
+// runCount returns how many times tailwindcss ran
+func (p *project) runCount(t *testing.T) int {
+	t.Helper()
+	data, err := os.ReadFile(p.runs)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	require.NoError(t, err)
+	return strings.Count(string(data), "run\n")
+}
+
+// hashedOutput returns the path of the hashed output of the app target recorded in the manifest
+func (p *project) hashedOutput(t *testing.T) string {
+	t.Helper()
+	m, err := asset.ReadManifest(filepath.Join(p.dir, "dist", asset.ManifestFileName))
+	require.NoError(t, err)
+	return filepath.Join(p.dir, "dist", m.Path("app.css"))
+}
+
+func TestBuildIncremental(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		setup  func(p *project)
+		change func(t *testing.T, p *project)
+		args   []string
+		runs   int
+	}{
+		{
+			name: "Unchanged",
+			runs: 1,
+		},
+		{
+			name: "Source changed",
+			change: func(t *testing.T, p *project) {
+				writeFile(t, p.dir, "templates/index.html", `<div class="grid"></div>`)
+			},
+			runs: 2,
+		},
+		{
+			name:   "Source added",
+			change: func(t *testing.T, p *project) { writeFile(t, p.dir, "templates/about.html", `<div></div>`) },
+			runs:   2,
+		},
+		{
+			name:   "Hidden file changed",
+			change: func(t *testing.T, p *project) { writeFile(t, p.dir, ".env", "KEY=value") },
+			runs:   1,
+		},
+		{
+			name: "Input changed",
+			change: func(t *testing.T, p *project) {
+				writeFile(t, p.dir, "styles/input.css", `@import "tailwindcss" source(none);`)
+			},
+			runs: 2,
+		},
+		{
+			name:   "Args changed",
+			change: func(t *testing.T, p *project) { p.cfg.Targets[0].Args = []string{"--minify"} },
+			runs:   2,
+		},
+		{
+			name: "Version changed",
+			change: func(t *testing.T, p *project) {
+				installScript(t, p.opts.CacheDir, "v4.1.0", fmt.Sprintf(fakeTailwind, p.runs, ".flex{display:flex}"))
+				p.opts.Version = "v4.1.0"
+			},
+			runs: 2,
+		},
+		{
+			name:   "Output deleted",
+			change: func(t *testing.T, p *project) { require.NoError(t, os.Remove(filepath.Join(p.dir, "dist", "app.css"))) },
+			runs:   2,
+		},
+		{
+			name: "Forced",
+			args: []string{"-force"},
+			runs: 2,
+		},
+		{
+			name:  "Hashed outputs are not sources",
+			setup: func(p *project) { p.cfg.Hash = true },
+			runs:  1,
+		},
+		{
+			name:   "Hashed output deleted",
+			setup:  func(p *project) { p.cfg.Hash = true },
+			change: func(t *testing.T, p *project) { require.NoError(t, os.Remove(p.hashedOutput(t))) },
+			runs:   2,
+		},
+		{
+			name: "Compressed outputs are not sources",
+			setup: func(p *project) {
+				p.cfg.Hash = true
+				p.cfg.Compress = &config.Compress{Gzip: true, Brotli: true}
+			},
+			runs: 1,
+		},
+		{
+			name:  "Generated Go is not a source",
+			setup: func(p *project) { p.cfg.Codegen = &config.Codegen{File: "dist/assets.go"} },
+			runs:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := newProject(t, ".flex{display:flex}")
+			p.cfg.Incremental = true
+			if tt.setup != nil {
+				tt.setup(p)
+			}
+			require.NoError(t, p.build())
+
+			if tt.change != nil {
+				tt.change(t, p)
+			}
+			require.NoError(t, p.build(tt.args...))
+
+			assert.Equal(t, tt.runs, p.runCount(t))
+			output := filepath.Join(p.dir, "dist", "app.css")
+			if p.cfg.Hash {
+				output = p.hashedOutput(t)
+			}
+			assert.FileExists(t, output)
+		})
+	}
+
+	t.Run("Records fingerprints of built targets", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+		p.cfg.Incremental = true
+
+		require.NoError(t, p.build())
+
+		stored, err := fingerprint.Read(fingerprint.Path(filepath.Join(p.dir, "dist", "app.css")))
+		require.NoError(t, err)
+		assert.NotEmpty(t, stored)
+	})
+
+	t.Run("Failed build is rebuilt", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+		p.cfg.Incremental = true
+		require.NoError(t, p.build())
+		fingerprintPath := fingerprint.Path(filepath.Join(p.dir, "dist", "app.css"))
+		require.FileExists(t, fingerprintPath)
+
+		installScript(t, p.opts.CacheDir, "v4.0.0", fmt.Sprintf(`echo run >> "%s"; exit 1`, p.runs))
+		writeFile(t, p.dir, "templates/index.html", `<div class="grid"></div>`)
+		require.ErrorIs(t, p.build(), main.ErrBuildFailed)
+		assert.NoFileExists(t, fingerprintPath, "the fingerprint of a failed build is removed")
+
+		installScript(t, p.opts.CacheDir, "v4.0.0", fmt.Sprintf(fakeTailwind, p.runs, ".flex{display:flex}"))
+		writeFile(t, p.dir, "templates/index.html", `<div class="flex"></div>`)
+		require.NoError(t, p.build())
+		assert.Equal(t, 3, p.runCount(t))
+	})
+
+	t.Run("Not incremental", func(t *testing.T) {
+		t.Parallel()
+		p := newProject(t, ".flex{display:flex}")
+
+		require.NoError(t, p.build())
+		require.NoError(t, p.build())
+
+		assert.Equal(t, 2, p.runCount(t))
+		assert.NoFileExists(t, fingerprint.Path(filepath.Join(p.dir, "dist", "app.css")))
+	})
+}
+
+func TestGeneratedBy(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	external := filepath.Join(t.TempDir(), "public", "admin.css")
+	generated := main.GeneratedBy(config.Config{
+		Path: filepath.Join(dir, config.FileName),
+		Targets: []config.Target{
+			{Target: tailwind.Target{Name: "app", Input: "styles/input.css", Output: "dist/app.css"}},
+			{Target: tailwind.Target{Name: "admin", Input: "styles/admin.css", Output: external}},
+		},
+		Codegen: &config.Codegen{File: "dist/assets.go"},
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		expected bool
+	}{
+		{"Output", filepath.Join(dir, "dist", "app.css"), true},
+		{"Hashed output", filepath.Join(dir, "dist", "app.3f9a1c2b.css"), true},
+		{"Gzip sibling", filepath.Join(dir, "dist", "app.css.gz"), true},
+		{"Hashed brotli sibling", filepath.Join(dir, "dist", "app.3f9a1c2b.css.br"), true},
+		{"Manifest", filepath.Join(dir, "dist", asset.ManifestFileName), true},
+		{"Generated Go", filepath.Join(dir, "dist", "assets.go"), true},
+		{"Absolute output", external, true},
+		{"Manifest of absolute output", filepath.Join(filepath.Dir(external), asset.ManifestFileName), true},
+		{"Other CSS in output directory", filepath.Join(dir, "dist", "other.css"), false},
+		{"Output name in other directory", filepath.Join(dir, "templates", "app.css"), false},
+		{"Manifest in other directory", filepath.Join(dir, "styles", asset.ManifestFileName), false},
+		{"Input", filepath.Join(dir, "styles", "input.css"), false},
+		{"Template", filepath.Join(dir, "templates", "index.html"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, generated(tt.path))
+		})
+	}
+}
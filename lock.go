@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Piszmog/go-tw/client"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/lockfile"
+	"github.com/Piszmog/go-tw/semver"
+	"github.com/Piszmog/go-tw/tailwind"
+)
+
+var ErrUnexpectedArg = errors.New("unexpected argument")
+
+// lock regenerates the lockfile at the module root, pinning the requested or configured
+// version (or the latest release) along with the checksum of the binary for every supported
+// platform the release publishes one for.
+func lock(ctx context.Context, logger *slog.Logger, c *client.Client, cfg config.Config, wd string, version string, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnexpectedArg, args[0])
+	}
+
+	root, err := fs.FindModuleRoot(wd)
+	if err != nil {
+		return fmt.Errorf("failed to find module root: %w", err)
+	}
+
+	if version == "" {
+		version = cfg.Version
+	}
+	if version == "" || version == VersionLatest {
+		version, err = c.GetLatestVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to determine latest version: %w", err)
+		}
+	} else {
+		constraint, parseErr := semver.ParseConstraint(version)
+		if parseErr != nil {
+			return fmt.Errorf("failed to parse version: %w", parseErr)
+		}
+		if v, ok := constraint.Exact(); ok {
+			version = v.String()
+		} else if version, err = tailwind.HighestRelease(ctx, logger, c, constraint); err != nil {
+			return err
+		}
+	}
+	logger.Debug("Locking version", "version", version)
+
+	checksums, err := c.GetChecksums(ctx, version)
+	if err != nil {
+		return fmt.Errorf("failed to get checksums for tailwindcss %s: %w", version, err)
+	}
+
+	l := lockfile.Lock{
+		Version: version,
+		Assets:  make(map[string]string),
+	}
+	// Not every release publishes every asset, e.g. the musl builds, so only those published are
+	// pinned and a platform without one fails when it installs tailwindcss
+	for _, asset := range client.GetAssetNames() {
+		checksum, ok := checksums[asset]
+		if !ok {
+			logger.Debug("Release does not publish asset, not pinning it", "version", version, "asset", asset)
+			continue
+		}
+		l.Assets[asset] = checksum
+	}
+	if len(l.Assets) == 0 {
+		return fmt.Errorf("%w: tailwindcss %s publishes no supported asset", client.ErrChecksumNotFound, version)
+	}
+
+	path := lockfile.Path(root)
+	if err = lockfile.Write(path, l); err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockfile.FileName, err)
+	}
+
+	fmt.Println("Locked tailwindcss " + version + " in " + path)
+	return nil
+}
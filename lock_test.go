@@ -0,0 +1,191 @@
+package main_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	main "github.com/Piszmog/go-tw"
+	"github.com/Piszmog/go-tw/client"
+	"github.com/Piszmog/go-tw/config"
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/lockfile"
+	"github.com/Piszmog/go-tw/tailwind"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// releaseServer serves tailwindcss releases, each publishing the checksums of its assets
+func releaseServer(t *testing.T, latest string, releases map[string][]string) *client.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/releases/latest":
+			_ = json.NewEncoder(w).Encode(map[string]string{"tag_name": latest})
+		case r.URL.Path == "/releases":
+			var tags []map[string]string
+			for version := range releases {
+				tags = append(tags, map[string]string{"tag_name": version})
+			}
+			_ = json.NewEncoder(w).Encode(tags)
+		case strings.HasSuffix(r.URL.Path, "/sha256sums.txt"):
+			assets, ok := releases[strings.Trim(strings.TrimSuffix(r.URL.Path, "sha256sums.txt"), "/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			for _, asset := range assets {
+				_, _ = w.Write([]byte(checksum(asset) + "  ./" + asset + "\n"))
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return client.New(slog.New(slog.DiscardHandler), time.Second).
+		WithTestURLs(server.URL, server.URL+"/releases/latest").
+		WithRetryAttempts(1)
+}
+
+// checksum returns the checksum the release server publishes for the asset
+func checksum(asset string) string {
+	sum := sha256.Sum256([]byte(asset))
+	return hex.EncodeToString(sum[:])
+}
+
+// newModule creates a Go module, returning its root
+func newModule(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, root, "go.mod", "module example.com/app\n")
+	return root
+}
+
+func TestLock(t *testing.T) {
+	t.Parallel()
+
+	// Older releases do not publish the musl builds
+	withoutMusl := slices.DeleteFunc(client.GetAssetNames(), func(asset string) bool {
+		return strings.HasSuffix(asset, "-musl")
+	})
+	releases := map[string][]string{
+		"v4.1.0":  client.GetAssetNames(),
+		"v4.0.0":  withoutMusl,
+		"v3.4.17": withoutMusl,
+	}
+
+	tests := []struct {
+		name       string
+		version    string
+		cfgVersion string
+		expected   string
+	}{
+		{name: "Latest", expected: "v4.1.0"},
+		{name: "Latest selector", version: tailwind.VersionLatest, expected: "v4.1.0"},
+		{name: "Exact version", version: "v4.0.0", expected: "v4.0.0"},
+		{name: "Constraint", version: "~3.4", expected: "v3.4.17"},
+		{name: "Configured version", cfgVersion: "4.0.x", expected: "v4.0.0"},
+		{name: "Flag over configured version", version: "v4.1.0", cfgVersion: "v4.0.0", expected: "v4.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := releaseServer(t, "v4.1.0", releases)
+			root := newModule(t)
+			cfg := config.Default()
+			cfg.Version = tt.cfgVersion
+
+			err := main.Lock(context.Background(), slog.New(slog.DiscardHandler), c, cfg, root, tt.version, nil)
+
+			require.NoError(t, err)
+			l, err := lockfile.Read(lockfile.Path(root))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, l.Version)
+			require.Len(t, l.Assets, len(releases[tt.expected]))
+			for _, asset := range releases[tt.expected] {
+				assert.Equal(t, checksum(asset), l.Assets[asset], asset)
+			}
+		})
+	}
+
+	t.Run("Assets the release does not publish are left out", func(t *testing.T) {
+		t.Parallel()
+		c := releaseServer(t, "v4.1.0", releases)
+		root := newModule(t)
+
+		require.NoError(t, main.Lock(context.Background(), slog.New(slog.DiscardHandler), c, config.Default(), root, "v4.0.0", nil))
+
+		l, err := lockfile.Read(lockfile.Path(root))
+		require.NoError(t, err)
+		_, err = l.Checksum(client.GetAssetName("linux", "amd64", true))
+		require.ErrorIs(t, err, lockfile.ErrMissingAsset)
+		checksum, err := l.Checksum(client.GetAssetName("linux", "amd64", false))
+		require.NoError(t, err)
+		assert.NotEmpty(t, checksum)
+	})
+
+	t.Run("Writes to the module root", func(t *testing.T) {
+		t.Parallel()
+		c := releaseServer(t, "v4.1.0", releases)
+		root := newModule(t)
+		wd := filepath.Join(root, "web", "styles")
+		writeFile(t, wd, "input.css", `@import "tailwindcss";`)
+
+		require.NoError(t, main.Lock(context.Background(), slog.New(slog.DiscardHandler), c, config.Default(), wd, "", nil))
+
+		assert.FileExists(t, lockfile.Path(root))
+		assert.NoFileExists(t, lockfile.Path(wd))
+	})
+
+	t.Run("Release publishes no supported asset", func(t *testing.T) {
+		t.Parallel()
+		c := releaseServer(t, "v4.1.0", map[string][]string{"v4.1.0": {"tailwindcss-freebsd-x64"}})
+		root := newModule(t)
+
+		err := main.Lock(context.Background(), slog.New(slog.DiscardHandler), c, config.Default(), root, "", nil)
+
+		require.ErrorIs(t, err, client.ErrChecksumNotFound)
+		assert.NoFileExists(t, lockfile.Path(root))
+	})
+
+	t.Run("No release satisfies constraint", func(t *testing.T) {
+		t.Parallel()
+		c := releaseServer(t, "v4.1.0", releases)
+		root := newModule(t)
+
+		err := main.Lock(context.Background(), slog.New(slog.DiscardHandler), c, config.Default(), root, "^5", nil)
+
+		require.ErrorIs(t, err, tailwind.ErrNoMatchingVersion)
+		assert.NoFileExists(t, lockfile.Path(root))
+	})
+
+	t.Run("Outside a module", func(t *testing.T) {
+		t.Parallel()
+		c := releaseServer(t, "v4.1.0", releases)
+
+		err := main.Lock(context.Background(), slog.New(slog.DiscardHandler), c, config.Default(), t.TempDir(), "", nil)
+
+		require.ErrorIs(t, err, fs.ErrModuleRootNotFound)
+	})
+
+	t.Run("Unexpected argument", func(t *testing.T) {
+		t.Parallel()
+		c := releaseServer(t, "v4.1.0", releases)
+		root := newModule(t)
+
+		err := main.Lock(context.Background(), slog.New(slog.DiscardHandler), c, config.Default(), root, "", []string{"v4.0.0"})
+
+		require.ErrorIs(t, err, main.ErrUnexpectedArg)
+		assert.NoFileExists(t, lockfile.Path(root))
+	})
+}
@@ -0,0 +1,71 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the name of the lockfile stored at the module root.
+const FileName = "go-tw.lock"
+
+// Lock pins the tailwindcss version of a project along with the SHA-256 digest of
+// the binary for every supported platform the release publishes one for.
+type Lock struct {
+	Version string            `json:"version"`
+	Assets  map[string]string `json:"assets"`
+}
+
+// Checksum returns the pinned digest of the given release asset.
+func (l Lock) Checksum(asset string) (string, error) {
+	checksum, ok := l.Assets[asset]
+	if !ok || checksum == "" {
+		return "", fmt.Errorf("%w: %s", ErrMissingAsset, asset)
+	}
+	return checksum, nil
+}
+
+// Path returns the path of the lockfile in the given module root.
+func Path(root string) string {
+	return filepath.Join(root, FileName)
+}
+
+// Read reads and validates the lockfile at path.
+func Read(path string) (Lock, error) {
+	//nolint:gosec // G304: path is the lockfile at the module root
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Lock{}, ErrNotExist
+		}
+		return Lock{}, err
+	}
+
+	var lock Lock
+	if err = json.Unmarshal(data, &lock); err != nil {
+		return Lock{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if lock.Version == "" {
+		return Lock{}, fmt.Errorf("%w: missing version", ErrInvalid)
+	}
+
+	return lock, nil
+}
+
+// Write writes the lockfile to path.
+func Write(path string, lock Lock) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	//nolint:gosec // G306: the lockfile is committed alongside go.mod and is not sensitive
+	return os.WriteFile(path, data, 0644)
+}
+
+var ErrNotExist = errors.New("lockfile does not exist")
+var ErrInvalid = errors.New("invalid lockfile")
+var ErrMissingAsset = errors.New("lockfile has no checksum for asset")
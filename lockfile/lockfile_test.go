@@ -0,0 +1,72 @@
+package lockfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Piszmog/go-tw/lockfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWrite(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Round trip", func(t *testing.T) {
+		t.Parallel()
+		path := lockfile.Path(t.TempDir())
+		lock := lockfile.Lock{
+			Version: "v4.0.0",
+			Assets: map[string]string{
+				"tailwindcss-linux-x64": "abc",
+				"tailwindcss-macos-x64": "def",
+			},
+		}
+
+		require.NoError(t, lockfile.Write(path, lock))
+
+		actual, err := lockfile.Read(path)
+		require.NoError(t, err)
+		assert.Equal(t, lock, actual)
+	})
+
+	t.Run("Missing lockfile", func(t *testing.T) {
+		t.Parallel()
+		_, err := lockfile.Read(lockfile.Path(t.TempDir()))
+		assert.ErrorIs(t, err, lockfile.ErrNotExist)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), lockfile.FileName)
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+
+		_, err := lockfile.Read(path)
+		assert.ErrorIs(t, err, lockfile.ErrInvalid)
+	})
+
+	t.Run("Missing version", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), lockfile.FileName)
+		require.NoError(t, os.WriteFile(path, []byte(`{"assets":{}}`), 0600))
+
+		_, err := lockfile.Read(path)
+		assert.ErrorIs(t, err, lockfile.ErrInvalid)
+	})
+}
+
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+	lock := lockfile.Lock{
+		Version: "v4.0.0",
+		Assets:  map[string]string{"tailwindcss-linux-x64": "abc"},
+	}
+
+	checksum, err := lock.Checksum("tailwindcss-linux-x64")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", checksum)
+
+	_, err = lock.Checksum("tailwindcss-macos-arm64")
+	assert.ErrorIs(t, err, lockfile.ErrMissingAsset)
+}
@@ -5,16 +5,17 @@ import (
 	"os"
 )
 
-// New creates a new logger with the given level and output.
+// New creates a new logger with the given level and output. Logs are written to stderr, so they
+// are never mixed into the CSS tailwindcss writes to stdout.
 func New(level Level, output Output) *slog.Logger {
 	var h slog.Handler
 	switch output {
 	case OutputJSON:
-		h = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level.ToSlog()})
+		h = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level.ToSlog()})
 	case OutputText:
 		fallthrough
 	default:
-		h = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level.ToSlog()})
+		h = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level.ToSlog()})
 	}
 	return slog.New(h)
 }
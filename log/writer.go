@@ -0,0 +1,56 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+)
+
+// LineWriter is an io.Writer that logs every line written to it at debug level, e.g. to tee
+// the output of a command to the logger.
+type LineWriter struct {
+	logger *slog.Logger
+	msg    string
+	attrs  []any
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewLineWriter creates a LineWriter logging each line with msg and the attributes.
+func NewLineWriter(logger *slog.Logger, msg string, attrs ...any) *LineWriter {
+	return &LineWriter{logger: logger, msg: msg, attrs: attrs}
+}
+
+// Write logs the complete lines in p, buffering a trailing partial line until it is completed.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs the buffered partial line, if any.
+func (w *LineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *LineWriter) log(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	w.logger.Debug(w.msg, append(w.attrs, "line", string(line))...)
+}
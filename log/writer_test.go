@@ -0,0 +1,33 @@
+package log_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Piszmog/go-tw/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	w := log.NewLineWriter(logger, "Command output", "stream", "stderr")
+
+	_, _ = w.Write([]byte("first line\nsecond "))
+	_, _ = w.Write([]byte("line\r\npartial"))
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `msg="Command output" stream=stderr line="first line"`)
+	assert.Contains(t, lines[1], `line="second line"`)
+
+	w.Flush()
+
+	lines = strings.Split(strings.TrimSpace(logs.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[2], "line=partial")
+}
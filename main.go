@@ -1,168 +1,199 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"runtime"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/Piszmog/go-tw/client"
-	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/config"
 	"github.com/Piszmog/go-tw/log"
+	"github.com/Piszmog/go-tw/progress"
+	"github.com/Piszmog/go-tw/tailwind"
 )
 
 var ErrMissingVersionArg = errors.New("version flag passed but missing argument")
-var ErrUnsupportedPlatform = errors.New("unsupported platform")
+var ErrUnsupportedPlatform = tailwind.ErrUnsupportedPlatform
+
+// VersionLatest selects the most recent tailwindcss release
+const VersionLatest = tailwind.VersionLatest
+
+// forwardedSignals are forwarded to tailwindcss so it can shut down gracefully, e.g. in watch mode
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
 
 func main() {
 	if err := execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		// tailwindcss already reported why it failed, only its exit code is left to pass on
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(ExitCode(err))
+	}
+}
+
+// ExitCode returns the exit code go-tw exits with for the error, the exit code of tailwindcss
+// when it failed, following the shell convention of 128 + the signal when it was killed
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
 	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 1
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	if code := exitErr.ExitCode(); code > 0 {
+		return code
+	}
+	return 1
 }
 
 //nolint:cyclop // linear flow with early returns; splitting would obscure the sequence
 func execute() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to determine working directory: %w", err)
+	}
+
+	cfg, err := config.Load(wd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
 	logger := log.New(
-		log.GetLevel(),
-		log.GetOutput(),
+		log.ToLevel(cfg.LogLevel),
+		log.ToOutput(cfg.LogOutput),
 	)
+	logger.Debug("Loaded configuration", "path", cfg.Path)
 
-	operatingSystem := runtime.GOOS
-	arch := runtime.GOARCH
-
-	logger.Debug("Running platform", "os", operatingSystem, "arch", arch)
-	if !IsSupported(operatingSystem, arch) {
-		return fmt.Errorf("%w: OS '%s' and arch '%s'", ErrUnsupportedPlatform, operatingSystem, arch)
+	c := client.New(logger, time.Duration(cfg.Timeout)).WithRetryAttempts(cfg.RetryAttempts)
+	if cfg.MirrorURL != "" {
+		c.WithDownloadURL(cfg.MirrorURL)
 	}
+	if cfg.DownloadTemplate != "" {
+		c.WithDownloadTemplate(cfg.DownloadTemplate)
+	}
+	if cfg.APIURL != "" {
+		c.WithAPIURL(cfg.APIURL)
+	}
+	if cfg.GitHubToken != "" {
+		c.WithToken(cfg.GitHubToken)
+	}
+	ctx := context.Background()
 
-	c := client.New(logger, 3*time.Minute)
-
-	version, args, err := GetArgs(os.Args[1:])
+	parsed, err := GetArgs(os.Args[1:])
 	if err != nil {
 		return fmt.Errorf("failed to parse arguments: %w", err)
 	}
-
-	downloadDir, err := fs.GetDownloadDir()
-	if err != nil {
-		return fmt.Errorf("failed to determine directory to download tailwind to: %w", err)
+	offline := parsed.Offline || cfg.Offline
+	c.WithOffline(offline)
+	if !parsed.Quiet && !progress.Disabled() {
+		c.WithProgress(progress.New(logger, os.Stderr, progress.IsTerminal(os.Stderr)))
 	}
-	ctx := context.Background()
 
-	actualVersion := version
-	//nolint:nestif
-	if version == "latest" {
-		ver, verErr := c.GetLatestVersion(ctx)
-		if verErr != nil {
-			if errors.Is(verErr, client.ErrHTTP) {
-				currVer, currErr := fs.GetCurrentVersion(downloadDir)
-				if currErr != nil {
-					return fmt.Errorf("failed to check for latest version of tailwind and no version is installed: %w", currErr)
-				}
-				fmt.Println("failed to fetch latest tailwindcss version: falling back to installed version " + currVer)
-				actualVersion = currVer
-			} else {
-				return fmt.Errorf("failed to determine latest version: %w", verErr)
-			}
-		} else {
-			logger.Debug("Retrieved latest version", "version", ver)
-			actualVersion = ver
+	// Commands are handled by go-tw rather than passed through to tailwindcss
+	if len(parsed.Tailwind) > 0 {
+		switch parsed.Tailwind[0] {
+		case "lock":
+			return lock(ctx, logger, c, cfg, wd, parsed.Version, parsed.Tailwind[1:])
+		case "cache":
+			return cache(logger, cfg, parsed.Tailwind[1:])
 		}
 	}
 
-	fileName := fs.PrefixTailwind + actualVersion
-	if operatingSystem == "windows" {
-		fileName += ".exe"
+	version, args := parsed.Version, parsed.Tailwind
+	if version == "" {
+		version = cfg.Version
 	}
-	filePath := filepath.Join(downloadDir, fileName)
-
-	exists := true
-	err = fs.Exists(filePath)
-	if err != nil {
-		if errors.Is(err, fs.ErrFileNotExists) {
-			exists = false
-		} else {
-			return fmt.Errorf("failed to check if tailwind is already installed: %w", err)
-		}
+	opts := tailwind.Options{
+		Version:     version,
+		CacheDir:    cfg.CacheDir,
+		ProjectDir:  wd,
+		Offline:     offline,
+		LatestTTL:   time.Duration(cfg.LatestTTL),
+		LockTimeout: time.Duration(cfg.LockTimeout),
+		Logger:      logger,
+		Output:      os.Stderr,
+		Client:      c,
 	}
 
-	if !exists {
-		fmt.Println("Downloading tailwindcss " + actualVersion)
-		if err = c.Download(ctx, operatingSystem, arch, actualVersion, filePath, downloadDir); err != nil {
-			return fmt.Errorf("failed to download tailwind: %w", err)
-		}
-		if err = fs.MakeExecutable(filePath); err != nil {
-			return fmt.Errorf("failed to make tailwind executable: %w", err)
-		}
-		if err = fs.DeleteOtherVersions(logger, downloadDir, actualVersion); err != nil {
-			return fmt.Errorf("failed to delete older version: %w", err)
+	if len(args) > 0 && (args[0] == "build" || args[0] == "check") {
+		// Every tailwindcss process is interrupted when go-tw is
+		buildCtx, stop := signal.NotifyContext(ctx, forwardedSignals...)
+		defer stop()
+		if args[0] == "check" {
+			return check(buildCtx, opts, cfg, args[1:])
 		}
+		return build(buildCtx, opts, cfg, args[1:])
 	}
 
-	if err := run(ctx, logger, filePath, args); err != nil {
-		return fmt.Errorf("failed to run tailwind: %w", err)
+	// Arguments from the configuration file are relative to the file
+	runDir := ""
+	if len(args) == 0 && len(cfg.Args) > 0 {
+		args = cfg.Args
+		runDir = cfg.Dir()
 	}
-	return nil
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	return tailwind.Build(ctx, tailwind.BuildOptions{
+		Options: opts,
+		Args:    args,
+		Dir:     runDir,
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+		Signals: signals,
+	})
 }
 
 // IsSupported checks if the given OS and architecture combination is supported
 func IsSupported(os string, arch string) bool {
-	switch os {
-	case "windows", "darwin", "linux":
-		return arch == "amd64" || arch == "arm64"
-	default:
-		return false
-	}
+	return tailwind.IsSupported(os, arch)
+}
+
+// Args are the go-tw flags parsed from the command line
+type Args struct {
+	// Version is either "latest", an exact tag or a semver constraint such as "^4.1". It is
+	// empty when the flag is not provided.
+	Version string
+	// Offline prevents any network access
+	Offline bool
+	// Quiet disables reporting the progress of downloads
+	Quiet bool
+	// Tailwind are the remaining arguments, passed through to tailwindcss
+	Tailwind []string
 }
 
-// GetArgs parses command line arguments and extracts the version flag
-func GetArgs(args []string) (string, []string, error) {
-	var filteredArgs []string
-	version := "latest"
+// GetArgs parses command line arguments and extracts the go-tw flags
+func GetArgs(args []string) (Args, error) {
+	var parsed Args
 
 	for i := 0; i < len(args); i++ {
-		if args[i] == "-version" {
+		switch args[i] {
+		case "-version":
 			if i+1 >= len(args) {
-				return "", nil, ErrMissingVersionArg
+				return Args{}, ErrMissingVersionArg
 			}
-			version = args[i+1]
+			parsed.Version = args[i+1]
 			i++
-			continue
+		case "-offline":
+			parsed.Offline = true
+		case "-quiet":
+			parsed.Quiet = true
+		default:
+			parsed.Tailwind = append(parsed.Tailwind, args[i])
 		}
-		filteredArgs = append(filteredArgs, args[i])
 	}
-	return version, filteredArgs, nil
-}
-
-func run(ctx context.Context, logger *slog.Logger, path string, args []string) error {
-	logger.Debug("Running command", "path", path, "args", args)
-	cmd := exec.CommandContext(ctx, path, args...) //nolint:gosec // G204: path is the downloaded tailwindcss binary, not user input
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	outStr := stdout.String()
-	errStr := stderr.String()
-
-	logger.Debug("Command", "out", outStr, "err", errStr)
-
-	if err != nil {
-		return err
-	}
-
-	if len(outStr) > 0 && len(args) > 0 {
-		fmt.Println(outStr)
-	} else {
-		fmt.Println(errStr)
-	}
-
-	return nil
+	return parsed, nil
 }
@@ -1,13 +1,64 @@
 package main_test
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"syscall"
 	"testing"
 
 	main "github.com/Piszmog/go-tw"
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/tailwind"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// fakeTailwind is a tailwindcss that counts its runs in the first file formatted into it and
+// writes the CSS formatted into it to the output, creating its directory like tailwindcss
+const fakeTailwind = `echo run >> "%s"
+while [ $# -gt 0 ]; do
+	if [ "$1" = -o ]; then out=$2; fi
+	shift
+done
+mkdir -p "$(dirname "$out")"
+printf '%%s' '%s' > "$out"`
+
+// installScript installs a shell script as the version of tailwindcss in the cache directory
+func installScript(t *testing.T, cacheDir string, version string, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	path := filepath.Join(cacheDir, fs.PrefixTailwind+version)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0600))
+	require.NoError(t, fs.MakeExecutable(path))
+}
+
+// testOptions returns the options of a build with the tailwindcss installed in the cache directory
+func testOptions(cacheDir string, projectDir string) tailwind.Options {
+	return tailwind.Options{
+		Version:    "v4.0.0",
+		CacheDir:   cacheDir,
+		ProjectDir: projectDir,
+		Offline:    true,
+		Logger:     slog.New(slog.DiscardHandler),
+		Output:     io.Discard,
+	}
+}
+
+// writeFile writes the file at the path relative to dir, creating its directory
+func writeFile(t *testing.T, dir string, path string, content string) {
+	t.Helper()
+	path = filepath.Join(dir, path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
 func TestIsSupported(t *testing.T) {
 	t.Parallel()
 
@@ -46,20 +97,22 @@ func TestGetArgs(t *testing.T) {
 		name        string
 		args        []string
 		wantVersion string
+		wantOffline bool
+		wantQuiet   bool
 		wantArgs    []string
 		wantErr     error
 	}{
 		{
 			name:        "No arguments",
 			args:        []string{},
-			wantVersion: "latest",
+			wantVersion: "",
 			wantArgs:    nil,
 			wantErr:     nil,
 		},
 		{
 			name:        "No version flag",
 			args:        []string{"-i", "input.css", "-o", "output.css"},
-			wantVersion: "latest",
+			wantVersion: "",
 			wantArgs:    []string{"-i", "input.css", "-o", "output.css"},
 			wantErr:     nil,
 		},
@@ -77,6 +130,21 @@ func TestGetArgs(t *testing.T) {
 			wantArgs:    []string{"-i", "input.css"},
 			wantErr:     nil,
 		},
+		{
+			name:        "Offline flag",
+			args:        []string{"-offline", "-i", "input.css", "-version", "^4.1"},
+			wantVersion: "^4.1",
+			wantOffline: true,
+			wantArgs:    []string{"-i", "input.css"},
+			wantErr:     nil,
+		},
+		{
+			name:      "Quiet flag",
+			args:      []string{"-i", "input.css", "-quiet"},
+			wantQuiet: true,
+			wantArgs:  []string{"-i", "input.css"},
+			wantErr:   nil,
+		},
 		{
 			name:        "Version flag without argument",
 			args:        []string{"-version"},
@@ -97,16 +165,52 @@ func TestGetArgs(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			version, args, err := main.GetArgs(tt.args)
+			args, err := main.GetArgs(tt.args)
 
 			if tt.wantErr != nil {
 				require.Error(t, err)
 				assert.ErrorIs(t, err, tt.wantErr)
 			} else {
 				require.NoError(t, err)
-				assert.Equal(t, tt.wantVersion, version)
-				assert.Equal(t, tt.wantArgs, args)
+				assert.Equal(t, tt.wantVersion, args.Version)
+				assert.Equal(t, tt.wantOffline, args.Offline)
+				assert.Equal(t, tt.wantQuiet, args.Quiet)
+				assert.Equal(t, tt.wantArgs, args.Tailwind)
 			}
 		})
 	}
 }
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	tests := []struct {
+		name     string
+		script   string
+		expected int
+	}{
+		{"Exit code", "exit 3", 3},
+		{"Killed by signal", "kill -TERM $$", 128 + int(syscall.SIGTERM)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := exec.Command("sh", "-c", tt.script).Run()
+
+			require.Error(t, err)
+			assert.Equal(t, tt.expected, main.ExitCode(fmt.Errorf("failed to run tailwind: %w", err)))
+		})
+	}
+
+	t.Run("Other errors", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, 0, main.ExitCode(nil))
+		assert.Equal(t, 1, main.ExitCode(main.ErrUnsupportedPlatform))
+	})
+}
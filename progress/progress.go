@@ -0,0 +1,171 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	// redrawInterval limits how often the progress line is redrawn on a terminal
+	redrawInterval = 100 * time.Millisecond
+	// DefaultLogInterval is how often progress is logged when not writing to a terminal
+	DefaultLogInterval = 5 * time.Second
+)
+
+// Reporter reports the progress of a download. It is an io.Writer so it can be hooked into the
+// copy of the download, counting the bytes written.
+//
+// On a terminal a single line showing the bytes downloaded, percentage, speed and ETA is redrawn
+// in place. Otherwise, e.g. in CI, the progress is logged periodically.
+type Reporter struct {
+	logger      *slog.Logger
+	out         io.Writer
+	interactive bool
+	interval    time.Duration
+
+	offset  int64
+	total   int64
+	written int64
+	start   time.Time
+	last    time.Time
+}
+
+// New creates a reporter drawing to out when interactive, or logging to logger otherwise.
+func New(logger *slog.Logger, out io.Writer, interactive bool) *Reporter {
+	return &Reporter{
+		logger:      logger,
+		out:         out,
+		interactive: interactive,
+		interval:    DefaultLogInterval,
+	}
+}
+
+// WithInterval sets how often progress is logged when not interactive.
+func (r *Reporter) WithInterval(interval time.Duration) *Reporter {
+	r.interval = interval
+	return r
+}
+
+// Start begins reporting a download of total bytes, resuming after offset bytes that were
+// already downloaded. The total is negative when unknown.
+func (r *Reporter) Start(offset int64, total int64) {
+	r.offset = offset
+	r.total = total
+	r.written = 0
+	r.start = time.Now()
+	// The download was only just announced, so the first log is not due until after the interval
+	r.last = r.start
+	if r.interactive {
+		r.last = time.Time{}
+	}
+}
+
+// Write counts the bytes written, reporting the progress when it is due.
+func (r *Reporter) Write(p []byte) (int, error) {
+	r.written += int64(len(p))
+
+	now := time.Now()
+	interval := r.interval
+	if r.interactive {
+		interval = redrawInterval
+	}
+	if now.Sub(r.last) >= interval {
+		r.last = now
+		r.report(now, false)
+	}
+	return len(p), nil
+}
+
+// Finish reports the final progress of the download.
+func (r *Reporter) Finish() {
+	r.report(time.Now(), true)
+}
+
+// Abort ends the progress line of a download that failed, so it is not overwritten by what
+// is printed next.
+func (r *Reporter) Abort() {
+	if r.interactive && r.written > 0 {
+		_, _ = io.WriteString(r.out, "\n")
+	}
+}
+
+func (r *Reporter) report(now time.Time, done bool) {
+	received := r.offset + r.written
+	elapsed := now.Sub(r.start)
+	var speed float64
+	if elapsed > 0 {
+		speed = float64(r.written) / elapsed.Seconds()
+	}
+
+	if !r.interactive {
+		attrs := []any{"downloaded", FormatBytes(received), "speed", FormatBytes(int64(speed)) + "/s"}
+		if r.total > 0 {
+			attrs = append(attrs, "total", FormatBytes(r.total), "percent", percent(received, r.total))
+		}
+		if done {
+			r.logger.Info("Download finished", append(attrs, "duration", elapsed.Round(time.Millisecond))...)
+		} else {
+			r.logger.Info("Download progress", attrs...)
+		}
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString("\r" + FormatBytes(received))
+	if r.total > 0 {
+		fmt.Fprintf(&line, " / %s (%d%%)", FormatBytes(r.total), percent(received, r.total))
+	}
+	fmt.Fprintf(&line, " %s/s", FormatBytes(int64(speed)))
+	if !done && r.total > 0 && speed > 0 {
+		eta := time.Duration(float64(r.total-received) / speed * float64(time.Second))
+		fmt.Fprintf(&line, " ETA %s", eta.Round(time.Second))
+	}
+	// Clear the rest of the previous line, which may have been longer
+	line.WriteString("\x1b[K")
+	if done {
+		line.WriteString("\n")
+	}
+	_, _ = io.WriteString(r.out, line.String())
+}
+
+func percent(received int64, total int64) int64 {
+	return min(received*100/total, 100)
+}
+
+// IsTerminal reports whether f is an interactive terminal. Other character devices, e.g.
+// /dev/null, are not.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd())) //nolint:gosec // G115: file descriptors fit in an int
+}
+
+// Disabled reports whether progress reporting is turned off with the NO_PROGRESS environment
+// variable, e.g. NO_PROGRESS=1. A value that is not a boolean turns it off too.
+func Disabled() bool {
+	value := os.Getenv("NO_PROGRESS")
+	if value == "" {
+		return false
+	}
+	disabled, err := strconv.ParseBool(value)
+	return err != nil || disabled
+}
+
+// FormatBytes formats the size using binary units, e.g. 1.5 MiB
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,136 @@
+package progress_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/go-tw/progress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interactive redraws a single line", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		r := progress.New(slog.New(slog.DiscardHandler), &out, true)
+
+		r.Start(0, 2048)
+		_, _ = r.Write(make([]byte, 1024))
+		r.Finish()
+
+		lines := strings.Split(out.String(), "\r")
+		assert.Len(t, lines, 3)
+		assert.Contains(t, lines[1], "1.0 KiB / 2.0 KiB (50%)")
+		assert.Contains(t, lines[1], "ETA")
+		assert.Contains(t, lines[2], "1.0 KiB / 2.0 KiB (50%)")
+		assert.True(t, strings.HasSuffix(out.String(), "\n"))
+		assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+	})
+
+	t.Run("Interactive resume includes offset", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		r := progress.New(slog.New(slog.DiscardHandler), &out, true)
+
+		r.Start(1024, 2048)
+		_, _ = r.Write(make([]byte, 1024))
+		r.Finish()
+
+		assert.Contains(t, out.String(), "2.0 KiB / 2.0 KiB (100%)")
+	})
+
+	t.Run("Interactive abort ends the line", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		r := progress.New(slog.New(slog.DiscardHandler), &out, true)
+
+		r.Start(0, -1)
+		_, _ = r.Write(make([]byte, 10))
+		r.Abort()
+
+		assert.True(t, strings.HasSuffix(out.String(), "\n"))
+		assert.NotContains(t, out.String(), "%")
+	})
+
+	t.Run("Non-interactive logs periodically", func(t *testing.T) {
+		t.Parallel()
+		var logs bytes.Buffer
+		var out bytes.Buffer
+		r := progress.New(slog.New(slog.NewTextHandler(&logs, nil)), &out, false).WithInterval(10 * time.Millisecond)
+
+		r.Start(0, 2048)
+		_, _ = r.Write(make([]byte, 512))
+		time.Sleep(20 * time.Millisecond)
+		_, _ = r.Write(make([]byte, 512))
+		r.Finish()
+
+		assert.Empty(t, out.String())
+		assert.Equal(t, 1, strings.Count(logs.String(), "Download progress"))
+		assert.Contains(t, logs.String(), "percent=50")
+		assert.Contains(t, logs.String(), "Download finished")
+	})
+}
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{20 * 1024 * 1024, "20.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, progress.FormatBytes(tt.size))
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	t.Parallel()
+
+	devNull, err := os.Open(os.DevNull)
+	require.NoError(t, err)
+	defer func() {
+		_ = devNull.Close()
+	}()
+	assert.False(t, progress.IsTerminal(devNull))
+
+	f, err := os.CreateTemp(t.TempDir(), "progress")
+	require.NoError(t, err)
+	defer func() {
+		_ = f.Close()
+	}()
+	assert.False(t, progress.IsTerminal(f))
+}
+
+func TestDisabled(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "", expected: false},
+		{value: "0", expected: false},
+		{value: "false", expected: false},
+		{value: "1", expected: true},
+		{value: "true", expected: true},
+		{value: "yes", expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			t.Setenv("NO_PROGRESS", test.value)
+			assert.Equal(t, test.expected, progress.Disabled())
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a range of versions, e.g. "^4.1", "~4.0.7", ">=4.0 <5", "4.x" or an exact
+// version. Space separated ranges must all be satisfied, and "||" separates alternatives.
+type Constraint struct {
+	sets     [][]comparator
+	original string
+}
+
+type comparator struct {
+	op string
+	v  Version
+}
+
+func (c comparator) check(v Version) bool {
+	cmp := v.Compare(c.v)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
+// ParseConstraint parses a version constraint.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{original: s}
+
+	for alt := range strings.SplitSeq(s, "||") {
+		tokens := strings.Fields(strings.ReplaceAll(alt, ",", " "))
+		if len(tokens) == 0 {
+			return Constraint{}, fmt.Errorf("%w: %q", ErrInvalidConstraint, s)
+		}
+
+		set := []comparator{}
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+			// Allow a space between the operator and the version, e.g. ">= 4.0"
+			if strings.Trim(token, "<>=^~") == "" && i+1 < len(tokens) {
+				token += tokens[i+1]
+				i++
+			}
+
+			comparators, err := parseRange(token)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("%w: %q: %w", ErrInvalidConstraint, s, err)
+			}
+			set = append(set, comparators...)
+		}
+		c.sets = append(c.sets, set)
+	}
+
+	return c, nil
+}
+
+// parseRange converts a single range, such as "^4.1", into the comparators it is equivalent to.
+func parseRange(token string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if rest, ok := strings.CutPrefix(token, prefix); ok {
+			op, token = prefix, rest
+			break
+		}
+	}
+
+	major, minor, patch, pre, parts, err := parsePartial(token)
+	if err != nil {
+		return nil, err
+	}
+	lower := Version{Major: major, Minor: minor, Patch: patch, Prerelease: pre}
+
+	// A bare wildcard matches every version
+	if parts == 0 {
+		if op == "<" || op == ">" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidConstraint, op+token)
+		}
+		return nil, nil
+	}
+
+	switch op {
+	case "", "=":
+		if parts == 3 {
+			return []comparator{{op: "=", v: lower}}, nil
+		}
+		return []comparator{{op: ">=", v: lower}, {op: "<", v: nextPartial(lower, parts)}}, nil
+	case "^":
+		upper := Version{Major: major + 1}
+		switch {
+		case major > 0 || parts == 1:
+		case minor > 0 || parts == 2:
+			upper = Version{Minor: minor + 1}
+		default:
+			upper = Version{Patch: patch + 1}
+		}
+		return []comparator{{op: ">=", v: lower}, {op: "<", v: upper}}, nil
+	case "~":
+		upper := Version{Major: major, Minor: minor + 1}
+		if parts == 1 {
+			upper = Version{Major: major + 1}
+		}
+		return []comparator{{op: ">=", v: lower}, {op: "<", v: upper}}, nil
+	case ">=", "<":
+		return []comparator{{op: op, v: lower}}, nil
+	case ">":
+		if parts == 3 {
+			return []comparator{{op: ">", v: lower}}, nil
+		}
+		return []comparator{{op: ">=", v: nextPartial(lower, parts)}}, nil
+	case "<=":
+		if parts == 3 {
+			return []comparator{{op: "<=", v: lower}}, nil
+		}
+		return []comparator{{op: "<", v: nextPartial(lower, parts)}}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidConstraint, op)
+	}
+}
+
+// nextPartial returns the first version after every version matching a partial version,
+// e.g. 4.2.0 for "4.1".
+func nextPartial(v Version, parts int) Version {
+	if parts == 1 {
+		return Version{Major: v.Major + 1}
+	}
+	return Version{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// String returns the constraint as it was parsed.
+func (c Constraint) String() string {
+	return c.original
+}
+
+// Exact returns the version when the constraint matches exactly one version.
+func (c Constraint) Exact() (Version, bool) {
+	if len(c.sets) != 1 || len(c.sets[0]) != 1 || c.sets[0][0].op != "=" {
+		return Version{}, false
+	}
+	return c.sets[0][0].v, true
+}
+
+// Check reports whether the version satisfies the constraint. Prerelease versions only
+// satisfy a range that explicitly includes a prerelease of the same major, minor and patch.
+func (c Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		if checkSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkSet(set []comparator, v Version) bool {
+	for _, comp := range set {
+		if !comp.check(v) {
+			return false
+		}
+	}
+
+	if v.Prerelease == "" {
+		return true
+	}
+	for _, comp := range set {
+		if comp.v.Prerelease != "" && comp.v.sameCore(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Highest returns the highest of the versions satisfying the constraint, as it appears in
+// versions. Versions that are not valid semantic versions are ignored.
+func (c Constraint) Highest(versions []string) (string, bool) {
+	var best Version
+	found := false
+	for _, s := range versions {
+		v, err := Parse(s)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		if !found || v.Compare(best) > 0 {
+			best = v
+			found = true
+		}
+	}
+	return best.Original, found
+}
@@ -0,0 +1,151 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version, e.g. v4.1.2 or v4.0.0-beta.1.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	// Original is the string the version was parsed from.
+	Original string
+}
+
+// Parse parses a full semantic version with an optional "v" prefix. Build metadata is ignored.
+func Parse(s string) (Version, error) {
+	major, minor, patch, pre, parts, err := parsePartial(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if parts != 3 {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+	return Version{Major: major, Minor: minor, Patch: patch, Prerelease: pre, Original: s}, nil
+}
+
+// String returns the canonical form of the version, prefixed with "v" like tailwindcss release tags.
+func (v Version) String() string {
+	s := "v" + strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is less than, equal to or greater than o.
+func (v Version) Compare(o Version) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func (v Version) sameCore(o Version) bool {
+	return v.Major == o.Major && v.Minor == o.Minor && v.Patch == o.Patch
+}
+
+func compareInt(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePrerelease compares prerelease identifiers per the semver specification. A version
+// without a prerelease has a higher precedence than one with.
+func comparePrerelease(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = compareInt(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(as), len(bs))
+}
+
+// parsePartial parses a possibly partial version such as "4", "4.1", "4.x" or "v4.1.2-beta.1",
+// returning how many of the major, minor and patch parts were specified. Wildcard parts
+// ("x", "X" or "*") end the version.
+func parsePartial(s string) (int, int, int, string, int, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if v == "" {
+		return 0, 0, 0, "", 0, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	pre := ""
+	if i := strings.Index(v, "-"); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+		if pre == "" {
+			return 0, 0, 0, "", 0, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+	}
+
+	fields := strings.Split(v, ".")
+	if len(fields) > 3 {
+		return 0, 0, 0, "", 0, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+
+	var nums [3]int
+	parts := 0
+	for _, f := range fields {
+		if f == "x" || f == "X" || f == "*" {
+			break
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return 0, 0, 0, "", 0, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+		nums[parts] = n
+		parts++
+	}
+	if pre != "" && parts != 3 {
+		return 0, 0, 0, "", 0, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+
+	return nums[0], nums[1], nums[2], pre, parts, nil
+}
+
+var ErrInvalidVersion = errors.New("invalid version")
+var ErrInvalidConstraint = errors.New("invalid version constraint")
@@ -0,0 +1,164 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/Piszmog/go-tw/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"With v prefix", "v4.1.2", "v4.1.2", false},
+		{"Without v prefix", "4.1.2", "v4.1.2", false},
+		{"Prerelease", "v4.0.0-beta.1", "v4.0.0-beta.1", false},
+		{"Build metadata", "v4.0.0+abc", "v4.0.0", false},
+		{"Partial", "v4.1", "", true},
+		{"Not a version", "latest", "", true},
+		{"Empty prerelease", "v4.0.0-", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			v, err := semver.Parse(tt.input)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, semver.ErrInvalidVersion)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v.String())
+			assert.Equal(t, tt.input, v.Original)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"v4.0.0", "v4.0.0", 0},
+		{"v4.0.1", "v4.0.0", 1},
+		{"v4.0.0", "v4.1.0", -1},
+		{"v5.0.0", "v4.9.9", 1},
+		{"v4.0.0-beta.1", "v4.0.0", -1},
+		{"v4.0.0-beta.2", "v4.0.0-beta.10", -1},
+		{"v4.0.0-alpha.1", "v4.0.0-beta.1", -1},
+		{"v4.0.0-beta", "v4.0.0-beta.1", -1},
+		{"v4.0.0-1", "v4.0.0-alpha", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			t.Parallel()
+			a, err := semver.Parse(tt.a)
+			require.NoError(t, err)
+			b, err := semver.Parse(tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, a.Compare(b))
+			assert.Equal(t, -tt.want, b.Compare(a))
+		})
+	}
+}
+
+func TestConstraintCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{"^4.1", []string{"v4.1.0", "v4.1.11", "v4.9.0"}, []string{"v4.0.7", "v5.0.0", "v3.4.0", "v4.2.0-beta.1"}},
+		{"^0.2.3", []string{"v0.2.3", "v0.2.9"}, []string{"v0.3.0", "v0.2.2"}},
+		{"^0.0.3", []string{"v0.0.3"}, []string{"v0.0.4"}},
+		{"~4.0.7", []string{"v4.0.7", "v4.0.17"}, []string{"v4.0.6", "v4.1.0"}},
+		{"~4", []string{"v4.0.0", "v4.9.0"}, []string{"v5.0.0"}},
+		{">=4.0 <5", []string{"v4.0.0", "v4.1.11"}, []string{"v3.4.17", "v5.0.0"}},
+		{">= 4.0, < 5", []string{"v4.0.0"}, []string{"v5.0.0"}},
+		{"4.x", []string{"v4.0.0", "v4.1.11"}, []string{"v3.4.17", "v5.0.0"}},
+		{"4.1.x", []string{"v4.1.0", "v4.1.11"}, []string{"v4.2.0"}},
+		{"4", []string{"v4.0.0"}, []string{"v5.0.0"}},
+		{"v4.0.7", []string{"v4.0.7", "4.0.7"}, []string{"v4.0.8"}},
+		{">4.0", []string{"v4.1.0"}, []string{"v4.0.9"}},
+		{"<=4.1", []string{"v4.1.9"}, []string{"v4.2.0"}},
+		{"*", []string{"v1.0.0", "v4.1.0"}, []string{"v4.1.0-beta.1"}},
+		{"^3.4 || ^4.1", []string{"v3.4.17", "v4.1.0"}, []string{"v4.0.0"}},
+		{">=4.0.0-beta.1 <5", []string{"v4.0.0-beta.3", "v4.0.0"}, []string{"v4.1.0-beta.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			t.Parallel()
+			c, err := semver.ParseConstraint(tt.constraint)
+			require.NoError(t, err)
+
+			for _, s := range tt.match {
+				v, err := semver.Parse(s)
+				require.NoError(t, err)
+				assert.True(t, c.Check(v), "%s should satisfy %s", s, tt.constraint)
+			}
+			for _, s := range tt.noMatch {
+				v, err := semver.Parse(s)
+				require.NoError(t, err)
+				assert.False(t, c.Check(v), "%s should not satisfy %s", s, tt.constraint)
+			}
+		})
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"", "latest", "^", ">=4.0 ||", "4.1.2.3", "<*"} {
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+			_, err := semver.ParseConstraint(s)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConstraintExact(t *testing.T) {
+	t.Parallel()
+
+	c, err := semver.ParseConstraint("4.0.7")
+	require.NoError(t, err)
+	v, ok := c.Exact()
+	assert.True(t, ok)
+	assert.Equal(t, "v4.0.7", v.String())
+
+	c, err = semver.ParseConstraint("^4.0.7")
+	require.NoError(t, err)
+	_, ok = c.Exact()
+	assert.False(t, ok)
+}
+
+func TestConstraintHighest(t *testing.T) {
+	t.Parallel()
+
+	versions := []string{"v3.4.17", "v4.0.7", "v4.1.11", "v4.2.0-beta.1", "insiders", "v5.0.0"}
+
+	c, err := semver.ParseConstraint("^4.0")
+	require.NoError(t, err)
+	v, ok := c.Highest(versions)
+	assert.True(t, ok)
+	assert.Equal(t, "v4.1.11", v)
+
+	c, err = semver.ParseConstraint("^6")
+	require.NoError(t, err)
+	_, ok = c.Highest(versions)
+	assert.False(t, ok)
+}
@@ -0,0 +1,242 @@
+// Package serve serves the CSS built by go-tw over HTTP, e.g. from the embed.FS generated by
+// go-tw build.
+//
+//	http.Handle("/assets/", http.StripPrefix("/assets/", serve.New(web.FS)))
+package serve
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Piszmog/go-tw/asset"
+)
+
+const (
+	// ImmutableCacheControl is sent for hashed names, whose content never changes.
+	ImmutableCacheControl = "public, max-age=31536000, immutable"
+	// RevalidateCacheControl is sent for names that are not hashed, so clients check the ETag
+	// before reusing a cached copy.
+	RevalidateCacheControl = "no-cache"
+)
+
+// encodings are the precompressed siblings served in order of preference
+var encodings = []struct {
+	name string
+	ext  string
+}{
+	{name: "br", ext: asset.BrotliExt},
+	{name: "gzip", ext: asset.GzipExt},
+}
+
+// Handler serves the files of an fs.FS. Hashed names, e.g. app.3f9a1c2b.css, are cached
+// indefinitely, and a precompressed sibling, e.g. app.3f9a1c2b.css.br, is served in place of the
+// file when the client accepts its encoding.
+//
+// The files are expected not to change while served, as with an embed.FS. A file that does change
+// is served with a new ETag when its size or modification time changes too.
+type Handler struct {
+	fsys fs.FS
+	// etags caches the ETag of every file served, by etagKey
+	etags sync.Map
+}
+
+// etagKey identifies the version of a file an ETag was computed for
+type etagKey struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// New creates a handler serving the files of fsys. The path of the request is the name of the
+// file in fsys, so a prefix is stripped with http.StripPrefix.
+func New(fsys fs.FS) *Handler {
+	return &Handler{fsys: fsys}
+}
+
+// ServeHTTP serves the file named by the path of the request
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if !servable(name) {
+		http.NotFound(w, r)
+		return
+	}
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+
+	header := w.Header()
+	served, encoding := name, ""
+	if asset.TrimCompressed(name) == name {
+		header.Add("Vary", "Accept-Encoding")
+		served, encoding, info = h.negotiate(r, name, info)
+	}
+
+	content, err := readSeeker(h.fsys, served)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer func() {
+		_ = content.Close()
+	}()
+
+	etag, err := h.etag(served, info, content)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Only set once the content can be served, so an error is never cached or sent as encoded
+	header.Set("ETag", etag)
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	if asset.IsHashed(name) {
+		header.Set("Cache-Control", ImmutableCacheControl)
+	} else {
+		header.Set("Cache-Control", RevalidateCacheControl)
+	}
+
+	// The name of the file rather than the sibling, so the Content-Type is that of the CSS
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// negotiate returns the name, encoding and info of the precompressed sibling of the file the
+// client prefers, the file itself when it accepts none
+func (h *Handler) negotiate(r *http.Request, name string, info fs.FileInfo) (string, string, fs.FileInfo) {
+	accepted := acceptedEncodings(r.Header.Get("Accept-Encoding"))
+	served, encoding, bestQ := name, "", 0.0
+	for _, e := range encodings {
+		q := accepted.quality(e.name)
+		if q <= bestQ {
+			continue
+		}
+		siblingInfo, err := fs.Stat(h.fsys, name+e.ext)
+		if err != nil || !siblingInfo.Mode().IsRegular() {
+			continue
+		}
+		served, encoding, info, bestQ = name+e.ext, e.name, siblingInfo, q
+	}
+	return served, encoding, info
+}
+
+// etag returns the strong ETag of the content of the file, computed once for every version of it
+func (h *Handler) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := etagKey{name: name, size: info.Size(), modTime: info.ModTime()}
+	if etag, ok := h.etags.Load(key); ok {
+		return etag.(string), nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	h.etags.Store(key, etag)
+	return etag, nil
+}
+
+// servable reports whether the name may be served. Hidden files, e.g. the fingerprints and sizes
+// go-tw records next to outputs, are not.
+func servable(name string) bool {
+	if name == "" || !fs.ValidPath(name) {
+		return false
+	}
+	for part := range strings.SplitSeq(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// readSeekCloser is a file that can be served with http.ServeContent
+type readSeekCloser interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// readSeeker opens the file, reading it into memory when it cannot seek
+func readSeeker(fsys fs.FS, name string) (readSeekCloser, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if rs, ok := f.(readSeekCloser); ok {
+		return rs, nil
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return nopCloser{bytes.NewReader(content)}, nil
+}
+
+// nopCloser is a reader in memory with nothing to close
+type nopCloser struct {
+	*bytes.Reader
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// accepted is the quality of every encoding listed in an Accept-Encoding header
+type accepted map[string]float64
+
+// acceptedEncodings parses an Accept-Encoding header, e.g. "br;q=1.0, gzip;q=0.8, *;q=0.1"
+func acceptedEncodings(header string) accepted {
+	a := make(accepted)
+	for part := range strings.SplitSeq(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			key, value, ok := strings.Cut(param, "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "q") {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+		a[coding] = q
+	}
+	return a
+}
+
+// quality returns the quality of the encoding, that of "*" when it is not listed
+func (a accepted) quality(encoding string) float64 {
+	if q, ok := a[encoding]; ok {
+		return q
+	}
+	return a["*"]
+}
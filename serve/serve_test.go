@@ -0,0 +1,190 @@
+package serve_test
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Piszmog/go-tw/serve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"dist/app.3f9a1c2b.css":      {Data: []byte("body{color:red}")},
+		"dist/app.3f9a1c2b.css.gz":   {Data: []byte("gzip")},
+		"dist/app.3f9a1c2b.css.br":   {Data: []byte("brotli")},
+		"dist/admin.css":             {Data: []byte("body{color:blue}")},
+		"dist/admin.css.gz":          {Data: []byte("gzip")},
+		"dist/.admin.css.sizes.json": {Data: []byte("{}")},
+	}
+}
+
+// brokenFS fails to open the files it lists, as when reading them fails
+type brokenFS struct {
+	fstest.MapFS
+}
+
+func (brokenFS) Open(string) (fs.File, error) {
+	return nil, errors.New("read failed")
+}
+
+func serveRequest(h http.Handler, method string, target string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Caches hashed names indefinitely", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serveRequest(serve.New(testFS()), http.MethodGet, "/dist/app.3f9a1c2b.css", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "body{color:red}", rec.Body.String())
+		assert.Equal(t, serve.ImmutableCacheControl, rec.Header().Get("Cache-Control"))
+		assert.Equal(t, "text/css; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Regexp(t, `^"[A-Za-z0-9_-]+"$`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("Revalidates names that are not hashed", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serveRequest(serve.New(testFS()), http.MethodGet, "/dist/admin.css", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, serve.RevalidateCacheControl, rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Negotiates the encoding", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name           string
+			target         string
+			acceptEncoding string
+			encoding       string
+			body           string
+		}{
+			{name: "Prefers brotli", target: "/dist/app.3f9a1c2b.css", acceptEncoding: "gzip, deflate, br", encoding: "br", body: "brotli"},
+			{name: "Gzip", target: "/dist/app.3f9a1c2b.css", acceptEncoding: "gzip", encoding: "gzip", body: "gzip"},
+			{name: "Quality", target: "/dist/app.3f9a1c2b.css", acceptEncoding: "br;q=0.5, gzip;q=0.8", encoding: "gzip", body: "gzip"},
+			{name: "Excluded", target: "/dist/app.3f9a1c2b.css", acceptEncoding: "br;q=0, gzip;q=0", body: "body{color:red}"},
+			{name: "Wildcard", target: "/dist/app.3f9a1c2b.css", acceptEncoding: "*", encoding: "br", body: "brotli"},
+			{name: "Missing sibling", target: "/dist/admin.css", acceptEncoding: "br, gzip", encoding: "gzip", body: "gzip"},
+			{name: "None accepted", target: "/dist/app.3f9a1c2b.css", body: "body{color:red}"},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+
+				rec := serveRequest(serve.New(testFS()), http.MethodGet, test.target, map[string]string{"Accept-Encoding": test.acceptEncoding})
+
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Equal(t, test.encoding, rec.Header().Get("Content-Encoding"))
+				assert.Equal(t, test.body, rec.Body.String())
+				assert.Equal(t, "text/css; charset=utf-8", rec.Header().Get("Content-Type"))
+				assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+			})
+		}
+	})
+
+	t.Run("ETags differ by encoding", func(t *testing.T) {
+		t.Parallel()
+		h := serve.New(testFS())
+
+		identity := serveRequest(h, http.MethodGet, "/dist/app.3f9a1c2b.css", nil)
+		br := serveRequest(h, http.MethodGet, "/dist/app.3f9a1c2b.css", map[string]string{"Accept-Encoding": "br"})
+		again := serveRequest(h, http.MethodGet, "/dist/app.3f9a1c2b.css", nil)
+
+		assert.NotEqual(t, identity.Header().Get("ETag"), br.Header().Get("ETag"))
+		assert.Equal(t, identity.Header().Get("ETag"), again.Header().Get("ETag"))
+	})
+
+	t.Run("Not modified", func(t *testing.T) {
+		t.Parallel()
+		h := serve.New(testFS())
+		etag := serveRequest(h, http.MethodGet, "/dist/app.3f9a1c2b.css", map[string]string{"Accept-Encoding": "br"}).Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		rec := serveRequest(h, http.MethodGet, "/dist/app.3f9a1c2b.css", map[string]string{
+			"Accept-Encoding": "br",
+			"If-None-Match":   etag,
+		})
+
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, etag, rec.Header().Get("ETag"))
+		assert.Equal(t, serve.ImmutableCacheControl, rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serveRequest(serve.New(testFS()), http.MethodGet, "/dist/app.3f9a1c2b.css", map[string]string{"If-None-Match": `"stale"`})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "body{color:red}", rec.Body.String())
+	})
+
+	t.Run("Head", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serveRequest(serve.New(testFS()), http.MethodHead, "/dist/app.3f9a1c2b.css", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, "15", rec.Header().Get("Content-Length"))
+	})
+
+	t.Run("Method not allowed", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serveRequest(serve.New(testFS()), http.MethodPost, "/dist/app.3f9a1c2b.css", nil)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		t.Parallel()
+
+		for _, target := range []string{"/dist/missing.css", "/dist", "/", "/dist/.admin.css.sizes.json", "/../dist/admin.css.gz/x"} {
+			rec := serveRequest(serve.New(testFS()), http.MethodGet, target, nil)
+			assert.Equal(t, http.StatusNotFound, rec.Code, target)
+		}
+	})
+
+	t.Run("Errors are not cached or encoded", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serveRequest(serve.New(brokenFS{testFS()}), http.MethodGet, "/dist/app.3f9a1c2b.css", map[string]string{"Accept-Encoding": "br"})
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, rec.Header().Get("Cache-Control"))
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Empty(t, rec.Header().Get("ETag"))
+	})
+
+	t.Run("Strips prefix", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serveRequest(http.StripPrefix("/assets/", serve.New(testFS())), http.MethodGet, "/assets/dist/admin.css", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "body{color:blue}", rec.Body.String())
+	})
+}
@@ -0,0 +1,383 @@
+// Package tailwind installs and runs the tailwindcss standalone CLI, so Go programs such as
+// build tools and magefiles can build CSS without shelling out to go-tw.
+package tailwind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/Piszmog/go-tw/client"
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/lockfile"
+	"github.com/Piszmog/go-tw/log"
+)
+
+const (
+	// VersionLatest selects the most recent tailwindcss release
+	VersionLatest = "latest"
+	// DefaultTimeout is the timeout of HTTP requests when no client is provided
+	DefaultTimeout = 3 * time.Minute
+	// DefaultLockTimeout is how long to wait for another process using the cache directory
+	DefaultLockTimeout = 10 * time.Minute
+	// signalGracePeriod is how long tailwindcss has to exit after a signal before it is killed
+	signalGracePeriod = 10 * time.Second
+)
+
+// Options control how tailwindcss is installed.
+type Options struct {
+	// Version is "latest", an exact tag or a semver constraint such as "^4.1". When empty, the
+	// version pinned in the project's lockfile is used, or the latest release without one.
+	Version string
+	// CacheDir is the directory tailwindcss is installed to, go-tw in the user's cache
+	// directory when empty.
+	CacheDir string
+	// ProjectDir is a directory of the Go module whose lockfile pins the version and checksums,
+	// the working directory when empty.
+	ProjectDir string
+	// Offline prevents any network access, tailwindcss must already be installed.
+	Offline bool
+	// LatestTTL is how long the latest version is cached, zero always asks the GitHub API.
+	LatestTTL time.Duration
+	// LockTimeout is how long to wait for another process using the cache directory,
+	// DefaultLockTimeout when zero.
+	LockTimeout time.Duration
+
+	// Logger receives the logs, they are discarded when nil.
+	Logger *slog.Logger
+	// Output receives messages meant for the user, e.g. that tailwindcss is being downloaded.
+	// They are written to os.Stderr when nil, keeping stdout for the output of tailwindcss.
+	Output io.Writer
+	// Client downloads tailwindcss, a client with DefaultTimeout is used when nil. A copy of it is
+	// used, with its offline mode and latest version cache configured from these options.
+	Client *client.Client
+}
+
+// BuildOptions control how tailwindcss is installed and run.
+type BuildOptions struct {
+	Options
+
+	// Args are the arguments passed to tailwindcss, e.g. "-i", "input.css", "-o", "output.css".
+	Args []string
+	// Dir is the working directory of tailwindcss, the working directory when empty.
+	Dir string
+
+	// Stdin is the input of tailwindcss, e.g. the CSS when building with "-i -".
+	Stdin io.Reader
+	// Stdout receives the output of tailwindcss, e.g. the CSS when building with "-o -".
+	Stdout io.Writer
+	// Stderr receives the diagnostics of tailwindcss.
+	Stderr io.Writer
+
+	// Signals are forwarded to tailwindcss, which is killed if it has not exited within a grace
+	// period. When the context is done, tailwindcss is interrupted the same way.
+	Signals <-chan os.Signal
+}
+
+// Ensure installs tailwindcss if needed, returning the path of the binary and its version.
+func Ensure(ctx context.Context, opts Options) (string, string, error) {
+	inst, err := ensure(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	inst.release()
+	return inst.path, inst.version, nil
+}
+
+// Build installs tailwindcss if needed and runs it. When tailwindcss fails, the returned error
+// wraps the *exec.ExitError.
+func Build(ctx context.Context, opts BuildOptions) error {
+	// A signal while installing, e.g. Ctrl+C during a slow download, cancels the install
+	ensureCtx, cancel := context.WithCancel(ctx)
+	installed := make(chan struct{})
+	go func() {
+		select {
+		case <-opts.Signals:
+			cancel()
+		case <-installed:
+		}
+	}()
+	inst, err := ensure(ensureCtx, opts.Options)
+	close(installed)
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer inst.release()
+
+	if err = run(ctx, inst, opts, inst.release); err != nil {
+		return fmt.Errorf("failed to run tailwind: %w", err)
+	}
+	return nil
+}
+
+// IsSupported checks if the given OS and architecture combination is supported
+func IsSupported(os string, arch string) bool {
+	switch os {
+	case "windows", "darwin", "linux":
+		return arch == "amd64" || arch == "arm64"
+	default:
+		return false
+	}
+}
+
+// install is an installed tailwindcss binary, protected from concurrent go-tw processes
+// until it is released
+type install struct {
+	logger  *slog.Logger
+	path    string
+	version string
+	lock    *fs.Lock
+}
+
+// release releases the cache lock, logging rather than failing since the lock is advisory
+func (i install) release() {
+	if err := i.lock.Release(); err != nil {
+		i.logger.Warn("Failed to release cache lock", "error", err)
+	}
+}
+
+// ensure installs tailwindcss if needed, holding the cache lock until the install is released.
+//
+//nolint:cyclop // linear flow with early returns; splitting would obscure the sequence
+func ensure(ctx context.Context, opts Options) (install, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	out := opts.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	// The client of the caller is left as it was, these options only apply to this install
+	var c *client.Client
+	if opts.Client != nil {
+		c = opts.Client.Clone()
+	} else {
+		c = client.New(logger, DefaultTimeout)
+	}
+	c.WithOffline(opts.Offline)
+	lockTimeout := opts.LockTimeout
+	if lockTimeout == 0 {
+		lockTimeout = DefaultLockTimeout
+	}
+
+	operatingSystem := runtime.GOOS
+	arch := runtime.GOARCH
+
+	logger.Debug("Running platform", "os", operatingSystem, "arch", arch)
+	if !IsSupported(operatingSystem, arch) {
+		return install{}, fmt.Errorf("%w: OS '%s' and arch '%s'", ErrUnsupportedPlatform, operatingSystem, arch)
+	}
+
+	projectDir := opts.ProjectDir
+	if projectDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return install{}, fmt.Errorf("failed to determine working directory: %w", err)
+		}
+		projectDir = wd
+	}
+	projectLock, err := readLock(projectDir)
+	if err != nil {
+		return install{}, err
+	}
+	pinned := ""
+	if projectLock != nil {
+		pinned = projectLock.Version
+	}
+	version := opts.Version
+	if version == "" {
+		version = VersionLatest
+		if pinned != "" {
+			logger.Debug("Using version from lockfile", "version", pinned)
+			version = pinned
+		}
+	}
+
+	downloadDir, err := fs.ResolveDownloadDir(opts.CacheDir)
+	if err != nil {
+		return install{}, fmt.Errorf("failed to determine directory to download tailwind to: %w", err)
+	}
+	c.WithLatestCache(downloadDir, opts.LatestTTL)
+
+	r := resolver{logger: logger, out: out, c: c, downloadDir: downloadDir, offline: opts.Offline}
+	actualVersion, err := r.resolve(ctx, version, pinned)
+	if err != nil {
+		return install{}, err
+	}
+
+	fileName := fs.PrefixTailwind + actualVersion
+	if operatingSystem == "windows" {
+		fileName += ".exe"
+	}
+	filePath := filepath.Join(downloadDir, fileName)
+
+	// Hold the cache lock until tailwindcss has started, so a concurrent go-tw process cannot
+	// install the same binary at the same time or delete it before it runs
+	cacheLock, err := fs.AcquireLock(ctx, logger, downloadDir, lockTimeout)
+	if err != nil {
+		return install{}, fmt.Errorf("failed to lock cache directory: %w", err)
+	}
+	inst := install{logger: logger, path: filePath, version: actualVersion, lock: cacheLock}
+	installed := false
+	defer func() {
+		if !installed {
+			inst.release()
+		}
+	}()
+
+	if err = fs.SweepTempFiles(logger, downloadDir); err != nil {
+		logger.Warn("Failed to delete leftover temp files", "dir", downloadDir, "error", err)
+	}
+
+	exists := true
+	err = fs.Exists(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrFileNotExists) {
+			exists = false
+		} else {
+			return install{}, fmt.Errorf("failed to check if tailwind is already installed: %w", err)
+		}
+	}
+
+	if !exists && opts.Offline {
+		return install{}, fmt.Errorf("%w: tailwindcss %s is not installed in %s, run go-tw once without -offline or GO_TW_OFFLINE to download it", client.ErrOffline, actualVersion, downloadDir)
+	}
+
+	if !exists {
+		checksum := ""
+		if projectLock != nil && projectLock.Version == actualVersion {
+			checksum, err = projectLock.Checksum(client.GetName(operatingSystem, arch))
+			if err != nil {
+				return install{}, fmt.Errorf("failed to verify tailwind against %s, tailwindcss %s is not published for this platform or the lockfile is outdated, run 'go-tw lock' to regenerate it: %w", lockfile.FileName, actualVersion, err)
+			}
+		} else if projectLock != nil {
+			logger.Debug("Requested version differs from lockfile, not using pinned checksums", "version", actualVersion, "lockVersion", projectLock.Version)
+		}
+
+		_, _ = fmt.Fprintln(out, "Downloading tailwindcss "+actualVersion)
+		if err = c.Download(ctx, operatingSystem, arch, actualVersion, filePath, downloadDir, checksum); err != nil {
+			if errors.Is(err, fs.ErrChecksumMismatch) {
+				return install{}, fmt.Errorf("refusing to install tailwind, the download does not match the published checksum: %w", err)
+			}
+			return install{}, fmt.Errorf("failed to download tailwind: %w", err)
+		}
+		if err = fs.WriteAsset(filePath, client.GetName(operatingSystem, arch)); err != nil {
+			logger.Debug("Failed to record the platform of tailwind", "path", filePath, "error", err)
+		}
+	}
+
+	if err = fs.Touch(filePath); err != nil {
+		logger.Debug("Failed to record tailwind usage", "path", filePath, "error", err)
+	}
+
+	installed = true
+	return inst, nil
+}
+
+// readLock reads the lockfile at the root of the module containing dir. A nil lock is
+// returned when the project has no lockfile.
+func readLock(dir string) (*lockfile.Lock, error) {
+	root, err := fs.FindModuleRoot(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrModuleRootNotFound) {
+			return nil, nil //nolint:nilnil // a project outside a module has no lockfile
+		}
+		return nil, fmt.Errorf("failed to find module root: %w", err)
+	}
+
+	l, err := lockfile.Read(lockfile.Path(root))
+	if err != nil {
+		if errors.Is(err, lockfile.ErrNotExist) {
+			return nil, nil //nolint:nilnil // the lockfile is optional
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", lockfile.FileName, err)
+	}
+	return &l, nil
+}
+
+// run runs tailwindcss, calling started once the process has started or failed to. The output
+// is also logged when debugging.
+func run(ctx context.Context, inst install, opts BuildOptions, started func()) error {
+	logger := inst.logger
+	logger.Debug("Running command", "path", inst.path, "args", opts.Args, "dir", opts.Dir)
+
+	// The context interrupts tailwindcss below, rather than killing it outright
+	cmd := exec.Command(inst.path, opts.Args...) //nolint:gosec,noctx // G204: path is the downloaded tailwindcss binary, not user input
+	cmd.Dir = opts.Dir
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+
+	// Connecting files directly lets tailwindcss detect a terminal, so only tee when debugging
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		stdout := log.NewLineWriter(logger, "Command output", "stream", "stdout")
+		stderr := log.NewLineWriter(logger, "Command output", "stream", "stderr")
+		defer stdout.Flush()
+		defer stderr.Flush()
+		cmd.Stdout = teeWriter(opts.Stdout, stdout)
+		cmd.Stderr = teeWriter(opts.Stderr, stderr)
+	}
+
+	err := cmd.Start()
+	started()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go forwardSignals(ctx, logger, cmd.Process, opts.Signals, done)
+	err = cmd.Wait()
+	close(done)
+	return err
+}
+
+// teeWriter writes to both writers, w may be nil
+func teeWriter(w io.Writer, tee io.Writer) io.Writer {
+	if w == nil {
+		return tee
+	}
+	return io.MultiWriter(w, tee)
+}
+
+// forwardSignals forwards the first signal received, or an interrupt when the context is done,
+// to the process until done is closed, killing the process if it does not exit within
+// signalGracePeriod
+func forwardSignals(ctx context.Context, logger *slog.Logger, process *os.Process, signals <-chan os.Signal, done <-chan struct{}) {
+	var sig os.Signal
+	select {
+	case <-done:
+		return
+	case sig = <-signals:
+	case <-ctx.Done():
+		sig = os.Interrupt
+	}
+
+	logger.Debug("Forwarding signal to tailwindcss", "signal", sig)
+	// Windows cannot send signals other than kill to a process
+	if err := process.Signal(sig); err != nil {
+		logger.Debug("Failed to forward signal, killing tailwindcss", "signal", sig, "error", err)
+		_ = process.Kill()
+		return
+	}
+
+	timer := time.NewTimer(signalGracePeriod)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		logger.Warn("tailwindcss did not exit after the signal, killing it", "gracePeriod", signalGracePeriod)
+		_ = process.Kill()
+	}
+}
+
+var ErrUnsupportedPlatform = errors.New("unsupported platform")
+var ErrNoMatchingVersion = errors.New("no tailwindcss release satisfies version constraint")
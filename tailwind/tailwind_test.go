@@ -0,0 +1,246 @@
+package tailwind_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/go-tw/client"
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/lockfile"
+	"github.com/Piszmog/go-tw/tailwind"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper function to create a test logger that discards output
+func testLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// installScript installs a shell script as tailwindcss v4.0.0 in the cache directory
+func installScript(t *testing.T, cacheDir string, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	path := filepath.Join(cacheDir, fs.PrefixTailwind+"v4.0.0")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0600))
+	require.NoError(t, fs.MakeExecutable(path))
+}
+
+func TestEnsure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Downloads requested version", func(t *testing.T) {
+		t.Parallel()
+		content := []byte("fake tailwindcss binary content here")
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/sha256sums.txt") {
+				sum := sha256.Sum256(content)
+				_, _ = w.Write([]byte(hex.EncodeToString(sum[:]) + "  ./" + client.GetName(runtime.GOOS, runtime.GOARCH) + "\n"))
+				return
+			}
+			assert.Equal(t, "/v4.0.0/"+client.GetName(runtime.GOOS, runtime.GOARCH), r.URL.Path)
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+
+		cacheDir := t.TempDir()
+		older := filepath.Join(cacheDir, fs.PrefixTailwind+"v3.4.17")
+		require.NoError(t, os.WriteFile(older, []byte("older"), 0600))
+		var out bytes.Buffer
+		opts := tailwind.Options{
+			Version:    "v4.0.0",
+			CacheDir:   cacheDir,
+			ProjectDir: t.TempDir(),
+			Output:     &out,
+			Client:     client.New(testLogger(), 30*time.Second).WithTestURLs(server.URL, ""),
+		}
+
+		path, version, err := tailwind.Ensure(context.Background(), opts)
+
+		require.NoError(t, err)
+		assert.Equal(t, "v4.0.0", version)
+		assert.Equal(t, cacheDir, filepath.Dir(path))
+		//nolint:gosec // G304: Reading from test temp file, safe
+		written, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, content, written)
+		assert.Equal(t, "Downloading tailwindcss v4.0.0\n", out.String())
+		assert.FileExists(t, older, "other versions are left for cache prune")
+		installs, err := fs.ListInstalls(cacheDir)
+		require.NoError(t, err)
+		require.Len(t, installs, 2)
+		assert.Equal(t, "v4.0.0", installs[0].Version)
+		assert.Equal(t, client.GetName(runtime.GOOS, runtime.GOARCH), installs[0].Asset)
+
+		// The lock is released, so it can be ensured again without downloading
+		out.Reset()
+		opts.Offline = true
+		path2, _, err := tailwind.Ensure(context.Background(), opts)
+		require.NoError(t, err)
+		assert.Equal(t, path, path2)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("Lockfile without checksum for platform", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}))
+		defer server.Close()
+		projectDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte("module example.com/app\n"), 0600))
+		require.NoError(t, lockfile.Write(lockfile.Path(projectDir), lockfile.Lock{
+			Version: "v4.0.0",
+			Assets:  map[string]string{"tailwindcss-freebsd-x64": strings.Repeat("0", 64)},
+		}))
+		cacheDir := t.TempDir()
+
+		_, _, err := tailwind.Ensure(context.Background(), tailwind.Options{
+			CacheDir:   cacheDir,
+			ProjectDir: projectDir,
+			Output:     io.Discard,
+			Client:     client.New(testLogger(), 30*time.Second).WithTestURLs(server.URL, server.URL+"/latest"),
+		})
+
+		require.ErrorIs(t, err, lockfile.ErrMissingAsset)
+		assert.Contains(t, err.Error(), "not published for this platform")
+		_, err = fs.GetCurrentVersion(cacheDir)
+		assert.ErrorIs(t, err, fs.ErrNotInstalled)
+	})
+
+	t.Run("Offline without install", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := tailwind.Ensure(context.Background(), tailwind.Options{
+			CacheDir:   t.TempDir(),
+			ProjectDir: t.TempDir(),
+			Offline:    true,
+		})
+
+		assert.ErrorIs(t, err, client.ErrOffline)
+	})
+
+	t.Run("Leaves the client of the caller unchanged", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		c := client.New(testLogger(), 30*time.Second).WithTestURLs(server.URL, server.URL+"/latest").WithRetryAttempts(1)
+
+		_, _, err := tailwind.Ensure(context.Background(), tailwind.Options{
+			CacheDir:   t.TempDir(),
+			ProjectDir: t.TempDir(),
+			Offline:    true,
+			Client:     c,
+		})
+		require.ErrorIs(t, err, client.ErrOffline)
+
+		// The client still reaches the network
+		_, err = c.GetLatestVersion(context.Background())
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, client.ErrOffline)
+	})
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Runs tailwindcss with arguments and streams", func(t *testing.T) {
+		t.Parallel()
+		cacheDir := t.TempDir()
+		installScript(t, cacheDir, `echo "args: $*"; cat; echo warning >&2`)
+		var stdout, stderr bytes.Buffer
+
+		err := tailwind.Build(context.Background(), tailwind.BuildOptions{
+			Options: tailwind.Options{Version: "v4.0.0", CacheDir: cacheDir, ProjectDir: t.TempDir(), Offline: true},
+			Args:    []string{"-i", "-", "-o", "-"},
+			Stdin:   strings.NewReader("@import \"tailwindcss\";\n"),
+			Stdout:  &stdout,
+			Stderr:  &stderr,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "args: -i - -o -\n@import \"tailwindcss\";\n", stdout.String())
+		assert.Equal(t, "warning\n", stderr.String())
+	})
+
+	t.Run("Stdout only receives the output of tailwindcss when downloading", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("requires sh")
+		}
+		content := []byte("#!/bin/sh\necho '.flex{display:flex}'\n")
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/sha256sums.txt") {
+				sum := sha256.Sum256(content)
+				_, _ = w.Write([]byte(hex.EncodeToString(sum[:]) + "  ./" + client.GetName(runtime.GOOS, runtime.GOARCH) + "\n"))
+				return
+			}
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+		var stdout, stderr bytes.Buffer
+
+		err := tailwind.Build(context.Background(), tailwind.BuildOptions{
+			Options: tailwind.Options{
+				Version:    "v4.0.0",
+				CacheDir:   t.TempDir(),
+				ProjectDir: t.TempDir(),
+				Logger:     slog.New(slog.NewTextHandler(&stderr, &slog.HandlerOptions{Level: slog.LevelDebug})),
+				Output:     &stderr,
+				Client:     client.New(testLogger(), 30*time.Second).WithTestURLs(server.URL, ""),
+			},
+			Args:   []string{"-i", "-", "-o", "-"},
+			Stdout: &stdout,
+			Stderr: &stderr,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, ".flex{display:flex}\n", stdout.String())
+		assert.Contains(t, stderr.String(), "Downloading tailwindcss v4.0.0")
+	})
+
+	t.Run("Returns exit error", func(t *testing.T) {
+		t.Parallel()
+		cacheDir := t.TempDir()
+		installScript(t, cacheDir, "exit 3")
+
+		err := tailwind.Build(context.Background(), tailwind.BuildOptions{
+			Options: tailwind.Options{Version: "v4.0.0", CacheDir: cacheDir, ProjectDir: t.TempDir(), Offline: true},
+		})
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+	})
+
+	t.Run("Interrupts tailwindcss when context is done", func(t *testing.T) {
+		t.Parallel()
+		cacheDir := t.TempDir()
+		installScript(t, cacheDir, `trap "exit 7" INT; while true; do sleep 0.05; done`)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		err := tailwind.Build(ctx, tailwind.BuildOptions{
+			Options: tailwind.Options{Version: "v4.0.0", CacheDir: cacheDir, ProjectDir: t.TempDir(), Offline: true},
+		})
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 7, exitErr.ExitCode())
+	})
+}
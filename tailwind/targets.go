@@ -0,0 +1,124 @@
+package tailwind
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Target is a CSS bundle built by tailwindcss.
+type Target struct {
+	// Name identifies the target in results.
+	Name string `json:"name"`
+	// Input is the path of the input CSS file.
+	Input string `json:"input"`
+	// Output is the path the CSS is written to.
+	Output string `json:"output"`
+	// Args are additional arguments passed to tailwindcss, e.g. "--minify".
+	Args []string `json:"args"`
+}
+
+// args returns the arguments tailwindcss is run with to build the target
+func (t Target) args() []string {
+	return append([]string{"-i", t.Input, "-o", t.Output}, t.Args...)
+}
+
+// TargetsOptions control how targets are built.
+type TargetsOptions struct {
+	Options
+
+	// Targets are the CSS bundles to build.
+	Targets []Target
+	// Dir is the working directory of tailwindcss, paths of the targets are relative to it.
+	Dir string
+	// Concurrency is how many targets are built at the same time, the number of CPUs when zero.
+	Concurrency int
+}
+
+// TargetResult is the outcome of building a target.
+type TargetResult struct {
+	Target Target
+	// Output is what tailwindcss wrote to stdout and stderr while building the target.
+	Output []byte
+	// Duration is how long the build took.
+	Duration time.Duration
+	// Err is why the build failed, nil when it succeeded.
+	Err error
+}
+
+// BuildTargets installs tailwindcss once if needed and builds every target with it, running up
+// to Concurrency builds at the same time. A result is returned for every target, in the order
+// of the targets, even when the context is done. The error is only returned when tailwindcss
+// could not be installed.
+func BuildTargets(ctx context.Context, opts TargetsOptions) ([]TargetResult, error) {
+	inst, err := ensure(ctx, opts.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the install until every target has started, so tailwindcss is not deleted before then
+	var started sync.WaitGroup
+	started.Add(len(opts.Targets))
+	released := make(chan struct{})
+	go func() {
+		started.Wait()
+		inst.release()
+		close(released)
+	}()
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	inst.logger.Debug("Building targets", "targets", len(opts.Targets), "concurrency", concurrency)
+
+	results := make([]TargetResult, len(opts.Targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(opts.Targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = buildTarget(ctx, inst, opts, opts.Targets[i], started.Done)
+			}
+		}()
+	}
+	for i := range opts.Targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	<-released
+
+	return results, nil
+}
+
+// buildTarget runs tailwindcss for the target, capturing its output so concurrent builds do
+// not interleave
+func buildTarget(ctx context.Context, inst install, opts TargetsOptions, target Target, started func()) TargetResult {
+	result := TargetResult{Target: target}
+	if ctx.Err() != nil {
+		started()
+		result.Err = ctx.Err()
+		return result
+	}
+
+	var output bytes.Buffer
+	start := time.Now()
+	err := run(ctx, inst, BuildOptions{
+		Args:   target.args(),
+		Dir:    opts.Dir,
+		Stdout: &output,
+		Stderr: &output,
+	}, started)
+	result.Duration = time.Since(start)
+	result.Output = output.Bytes()
+	if err != nil {
+		result.Err = fmt.Errorf("failed to build %s: %w", target.Name, err)
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package tailwind_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/tailwind"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTargets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Builds every target", func(t *testing.T) {
+		t.Parallel()
+		cacheDir := t.TempDir()
+		dir := t.TempDir()
+		// Copies the input to the output, failing when the input is missing
+		installScript(t, cacheDir, `echo "building $2" >&2; cat "$2" > "$4"`)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app.css"), []byte("app"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "admin.css"), []byte("admin"), 0600))
+
+		results, err := tailwind.BuildTargets(context.Background(), tailwind.TargetsOptions{
+			Options: tailwind.Options{Version: "v4.0.0", CacheDir: cacheDir, ProjectDir: t.TempDir(), Offline: true},
+			Targets: []tailwind.Target{
+				{Name: "app", Input: "app.css", Output: "app.out.css"},
+				{Name: "missing", Input: "missing.css", Output: "missing.out.css"},
+				{Name: "admin", Input: "admin.css", Output: "admin.out.css", Args: []string{"--minify"}},
+			},
+			Dir:         dir,
+			Concurrency: 2,
+		})
+
+		require.NoError(t, err)
+		// The cache lock is released before returning, as the process may exit right after
+		assert.NoFileExists(t, filepath.Join(cacheDir, fs.LockFileName))
+		require.Len(t, results, 3)
+		assert.Equal(t, "app", results[0].Target.Name)
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, "building app.css\n", string(results[0].Output))
+		assert.Equal(t, "missing", results[1].Target.Name)
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, results[1].Err, &exitErr)
+		assert.Contains(t, string(results[1].Output), "missing.css")
+		assert.Equal(t, "admin", results[2].Target.Name)
+		require.NoError(t, results[2].Err)
+		for name, content := range map[string]string{"app.out.css": "app", "admin.out.css": "admin"} {
+			//nolint:gosec // G304: Reading from test temp file, safe
+			built, readErr := os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, readErr)
+			assert.Equal(t, content, string(built))
+		}
+	})
+
+	t.Run("Skips targets when context is done", func(t *testing.T) {
+		t.Parallel()
+		cacheDir := t.TempDir()
+		installScript(t, cacheDir, `trap "exit 7" INT; while true; do sleep 0.05; done`)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		results, err := tailwind.BuildTargets(ctx, tailwind.TargetsOptions{
+			Options: tailwind.Options{Version: "v4.0.0", CacheDir: cacheDir, ProjectDir: t.TempDir(), Offline: true},
+			Targets: []tailwind.Target{
+				{Name: "app", Input: "app.css", Output: "app.out.css"},
+				{Name: "admin", Input: "admin.css", Output: "admin.out.css"},
+			},
+			Concurrency: 1,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, results[0].Err, &exitErr)
+		assert.Equal(t, 7, exitErr.ExitCode())
+		assert.ErrorIs(t, results[1].Err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,98 @@
+package tailwind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/Piszmog/go-tw/client"
+	"github.com/Piszmog/go-tw/fs"
+	"github.com/Piszmog/go-tw/semver"
+)
+
+// resolver resolves version selectors against the installed and published releases
+type resolver struct {
+	logger      *slog.Logger
+	out         io.Writer
+	c           *client.Client
+	downloadDir string
+	offline     bool
+}
+
+// resolve resolves the version selector, either "latest", an exact tag or a semver
+// constraint, to the tag of a tailwindcss release. A constraint prefers the pinned version,
+// then the highest installed version, before consulting the published releases so the
+// version does not change unexpectedly. When offline, only the pinned and installed versions
+// are considered.
+//
+//nolint:cyclop // each resolution source is a short early return
+func (r resolver) resolve(ctx context.Context, selector string, pinned string) (string, error) {
+	if selector == VersionLatest && r.offline {
+		currVer, err := fs.GetCurrentVersion(r.downloadDir)
+		if err != nil {
+			return "", fmt.Errorf("%w: no tailwindcss version is installed in %s, run go-tw once without -offline or GO_TW_OFFLINE to download it: %w", client.ErrOffline, r.downloadDir, err)
+		}
+		r.logger.Debug("Using installed version in offline mode", "version", currVer)
+		return currVer, nil
+	}
+	if selector == VersionLatest {
+		ver, err := r.c.GetLatestVersion(ctx)
+		if err != nil {
+			if !errors.Is(err, client.ErrHTTP) && !errors.Is(err, client.ErrRateLimited) {
+				return "", fmt.Errorf("failed to determine latest version: %w", err)
+			}
+			currVer, currErr := fs.GetCurrentVersion(r.downloadDir)
+			if currErr != nil {
+				return "", fmt.Errorf("failed to check for latest version of tailwind and no version is installed: %w: %w", err, currErr)
+			}
+			_, _ = fmt.Fprintln(r.out, "failed to fetch latest tailwindcss version ("+err.Error()+"): falling back to installed version "+currVer)
+			return currVer, nil
+		}
+		r.logger.Debug("Retrieved latest version", "version", ver)
+		return ver, nil
+	}
+
+	constraint, err := semver.ParseConstraint(selector)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse version: %w", err)
+	}
+	if v, ok := constraint.Exact(); ok {
+		return v.String(), nil
+	}
+
+	if ver, ok := constraint.Highest([]string{pinned}); ok {
+		r.logger.Debug("Lockfile version satisfies constraint", "constraint", selector, "version", ver)
+		return ver, nil
+	}
+
+	installed, err := fs.GetInstalledVersions(r.downloadDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list installed versions: %w", err)
+	}
+	if ver, ok := constraint.Highest(installed); ok {
+		r.logger.Debug("Installed version satisfies constraint", "constraint", selector, "version", ver)
+		return ver, nil
+	}
+
+	if r.offline {
+		return "", fmt.Errorf("%w: no installed tailwindcss version satisfies %s, run go-tw once without -offline or GO_TW_OFFLINE to download one", client.ErrOffline, selector)
+	}
+
+	return HighestRelease(ctx, r.logger, r.c, constraint)
+}
+
+// HighestRelease resolves the constraint to the highest published release satisfying it
+func HighestRelease(ctx context.Context, logger *slog.Logger, c *client.Client, constraint semver.Constraint) (string, error) {
+	releases, err := c.ListVersions(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list tailwindcss releases: %w", err)
+	}
+	ver, ok := constraint.Highest(releases)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNoMatchingVersion, constraint)
+	}
+	logger.Debug("Resolved version from releases", "constraint", constraint, "version", ver)
+	return ver, nil
+}